@@ -0,0 +1,83 @@
+// cmd/import/main.go importe à la demande des films ou séries par identifiant TMDB,
+// comme POST /Films/{tmdbId} et POST /TvShows/{tmdbId} :
+//
+//	go run ./cmd/import -type movie -credits 550 603
+//	go run ./cmd/import -type tv -genres -recommendations 1399
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"mon-projet/internal/handlers"
+	"mon-projet/internal/scheduler"
+)
+
+func main() {
+	mediaType := flag.String("type", "movie", "type de titre : movie ou tv")
+	var opts handlers.ImportOptions
+	flag.BoolVar(&opts.Genres, "genres", false, "synchroniser les genres avant l'import")
+	flag.BoolVar(&opts.Credits, "credits", false, "importer aussi la distribution et l'équipe technique")
+	flag.BoolVar(&opts.Recommendations, "recommendations", false, "importer aussi les recommandations")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage : %s [options] tmdbId...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	importTitle := handlers.ImportFilm
+	switch *mediaType {
+	case "movie":
+	case "tv":
+		importTitle = handlers.ImportTvShow
+	default:
+		log.Fatalf("type %q inconnu (movie ou tv)", *mediaType)
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Les genres passent par le job "genres" et son verrou partagé, pour ne pas tourner en
+	// même temps que le serveur ; le scheduler n'est pas démarré, rien n'est planifié
+	if opts.Genres {
+		if err := handlers.RegisterJobs(scheduler.New()); err != nil {
+			log.Fatalf("Erreur enregistrement des jobs: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	failed := false
+	for _, arg := range flag.Args() {
+		tmdbID, err := strconv.Atoi(arg)
+		if err != nil {
+			log.Printf("❌ Identifiant TMDB invalide: %q", arg)
+			failed = true
+			continue
+		}
+		doc, created, err := importTitle(ctx, tmdbID, opts)
+		if err != nil {
+			log.Printf("❌ Import %s %d: %v", *mediaType, tmdbID, err)
+			failed = true
+			continue
+		}
+		if created {
+			log.Printf("✅ %s %d inséré", *mediaType, tmdbID)
+		} else {
+			log.Printf("🔄 %s %d mis à jour", *mediaType, tmdbID)
+		}
+		if err := enc.Encode(doc); err != nil {
+			log.Fatalf("Écriture JSON: %v", err)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -2,14 +2,27 @@
 package main
 
 import (
+    "context"
+    "errors"
     "log"
     "mon-projet/internal/handlers"
+    "mon-projet/internal/scheduler"
     "net/http"
     "fmt"
     "os"
+    "os/signal"
+    "syscall"
+    "time"
 )
 
 func main() {
+    // Planification des synchronisations (voir internal/scheduler)
+    sched := scheduler.New()
+    if err := handlers.RegisterJobs(sched); err != nil {
+        log.Fatalf("Erreur planification des synchronisations: %v", err)
+    }
+    sched.Start()
+
     // Routes HTTP
      // Route racine pour décrire l'API
     http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -18,18 +31,48 @@ func main() {
         fmt.Fprintln(w, "Routes disponibles :")
         fmt.Fprintln(w, "/Genre                  → Récuprèrer les Genres de film ou série TV")
         fmt.Fprintln(w, "/Films                  → Récuprèrer les films")
+        fmt.Fprintln(w, "POST /Films/{tmdbId}    → Importer tout de suite un film (?genres=true&credits=true&recommendations=true)")
         fmt.Fprintln(w, "/TvShows                → Récuprèrer les séries TV")
+        fmt.Fprintln(w, "POST /TvShows/{tmdbId}  → Importer tout de suite une série (mêmes options)")
+        fmt.Fprintln(w, "/Changes                → Mettre à jour les films et séries modifiés sur TMDB")
         fmt.Fprintln(w, "/FilmRecommendations    → Récuprèrer les Recommandations de films")
         fmt.Fprintln(w, "/TvShowsRecommendations → Récuprèrer les Recommandations de séries TV")
         fmt.Fprintln(w, "/Configurations         → Récuprèrer la Configuration TMDB")
+        fmt.Fprintln(w, "/People                 → Récuprèrer les acteurs et l'équipe technique des titres stockés")
+        fmt.Fprintln(w, "/PeopleDetails          → Récuprèrer la biographie et la filmographie des personnes stockées")
+        fmt.Fprintln(w, "/Videos                 → Récuprèrer les bandes-annonces et vidéos des titres stockés")
+        fmt.Fprintln(w, "/WatchProviders         → Récuprèrer où regarder les titres stockés (par pays)")
+        fmt.Fprintln(w, "/WatchProviderList      → Récuprèrer la liste des fournisseurs (Netflix, Canal+…)")
+        fmt.Fprintln(w, "/Certifications         → Récuprèrer les classifications d'âge et dates de sortie des titres stockés")
+        fmt.Fprintln(w, "/CertificationList      → Récuprèrer la liste des classifications d'âge par pays")
+        fmt.Fprintln(w, "/Keywords               → Récuprèrer les mots-clés des titres stockés")
+        fmt.Fprintln(w, "/FilmCollections        → Mettre à jour les sagas de films (parties et films stockés)")
+        fmt.Fprintln(w, "GET /search?q=&type=    → Rechercher sur TMDB (movie, tv, person ou multi), import=true pour importer les absents")
+        fmt.Fprintln(w, "/Lists                  → Récuprèrer les tendances, films à l'affiche, à venir, mieux notés et séries en cours")
+        fmt.Fprintln(w, "GET /Jobs               → Lister les synchronisations planifiées et leur prochaine exécution")
     })
 
     http.HandleFunc("/Genre", handlers.GenreTVShowHandler)
     http.HandleFunc("/Films", handlers.MovieHandler)
+    http.HandleFunc("POST /Films/{tmdbId}", handlers.ImportFilmHandler)
     http.HandleFunc("/TvShows", handlers.TvShowHandler)
+    http.HandleFunc("POST /TvShows/{tmdbId}", handlers.ImportTvShowHandler)
+    http.HandleFunc("/Changes", handlers.ChangesHandler)
     http.HandleFunc("/FilmRecommendations", handlers.FilmRecommendationHandler)
     http.HandleFunc("/TvShowsRecommendations", handlers.TvShowRecommendationHandler)
     http.HandleFunc("/Configurations", handlers.ConfigurationHandler)
+    http.HandleFunc("/People", handlers.PeopleHandler)
+    http.HandleFunc("/PeopleDetails", handlers.PeopleDetailsHandler)
+    http.HandleFunc("/Videos", handlers.VideosHandler)
+    http.HandleFunc("/WatchProviders", handlers.WatchProvidersHandler)
+    http.HandleFunc("/WatchProviderList", handlers.WatchProviderListHandler)
+    http.HandleFunc("/Certifications", handlers.CertificationsHandler)
+    http.HandleFunc("/CertificationList", handlers.CertificationListHandler)
+    http.HandleFunc("/Keywords", handlers.KeywordsHandler)
+    http.HandleFunc("/FilmCollections", handlers.FilmCollectionsHandler)
+    http.HandleFunc("/Lists", handlers.TitleListsHandler)
+    http.HandleFunc("GET /search", handlers.SearchHandler)
+    http.HandleFunc("GET /Jobs", handlers.JobsHandler)
 
     // Port dynamique (Render injecte la variable $PORT)
     port := os.Getenv("PORT")
@@ -37,8 +80,27 @@ func main() {
         port = "8081" // fallback local
     }
 
-    log.Printf("Serveur démarré sur le port %s…", port)
-    if err := http.ListenAndServe(":" + port, nil); err != nil {
-        log.Fatalf("Erreur au démarrage du serveur: %v", err)
+    // Arrêt propre sur SIGINT/SIGTERM (Render envoie SIGTERM au redéploiement) :
+    // plus de nouvelles requêtes, puis on attend les synchronisations en cours,
+    // qui libèrent leurs verrous partagés
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    srv := &http.Server{Addr: ":" + port}
+    go func() {
+        log.Printf("Serveur démarré sur le port %s…", port)
+        if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+            log.Fatalf("Erreur au démarrage du serveur: %v", err)
+        }
+    }()
+
+    <-ctx.Done()
+    log.Printf("🛑 Arrêt du serveur demandé, attente des synchronisations en cours…")
+    shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    if err := srv.Shutdown(shutdownCtx); err != nil {
+        log.Printf("⚠️ Arrêt du serveur HTTP: %v", err)
     }
+    sched.Stop()
+    log.Printf("✅ Serveur arrêté")
 }
@@ -3,6 +3,7 @@ module mon-projet
 go 1.23.6
 
 require (
-	github.com/joho/godotenv v1.5.1 // indirect
-	github.com/robfig/cron/v3 v3.0.1 // indirect
+	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.9.0
+	github.com/robfig/cron/v3 v3.0.1
 )
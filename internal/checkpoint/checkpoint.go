@@ -0,0 +1,77 @@
+// Package checkpoint conserve l'avancement de chaque synchronisation (curseur de page,
+// watermark de date, dernières réussites et pages en échec) indépendamment des données
+// synchronisées. Plusieurs backends implémentent Store : fichier local ou Strapi.
+package checkpoint
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Checkpoint est l'avancement d'un job de synchronisation.
+type Checkpoint struct {
+	// Job identifie la synchronisation (ex: "movies", "film-recommendations").
+	Job string `json:"job"`
+	// Cursor est la dernière page entièrement traitée ou sautée après échec.
+	Cursor int `json:"cursor"`
+	// Watermark est une borne de date (YYYY-MM-DD) pour les jobs incrémentaux.
+	Watermark string `json:"watermark,omitempty"`
+	// LastSuccessAt est la date de la dernière page entièrement réussie (voir MarkDone),
+	// même si une autre page du même passage a échoué.
+	LastSuccessAt time.Time `json:"last_success_at,omitempty"`
+	// LastFailedPage est la dernière page dont au moins un élément a échoué.
+	LastFailedPage int `json:"last_failed_page,omitempty"`
+	// FailedPages sont les pages à retenter, dans l'ordre où elles le seront (voir MarkFailed).
+	FailedPages []int `json:"failed_pages,omitempty"`
+	// UpdatedAt est renseigné par le Store à chaque Save.
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// Store lit et écrit les checkpoints.
+type Store interface {
+	// Load renvoie le checkpoint de job ; found vaut false s'il n'a jamais été enregistré.
+	Load(ctx context.Context, job string) (cp Checkpoint, found bool, err error)
+	// Save enregistre cp (identifié par cp.Job).
+	Save(ctx context.Context, cp Checkpoint) error
+}
+
+// Pending renvoie les pages à traiter lors du prochain passage : la première page en échec
+// (s'il y en a une, voir MarkFailed) puis la page qui suit le curseur.
+func (c *Checkpoint) Pending() []int {
+	next := c.Cursor + 1
+	if len(c.FailedPages) > 0 && c.FailedPages[0] != next {
+		return []int{c.FailedPages[0], next}
+	}
+	return []int{next}
+}
+
+// MarkDone enregistre que tous les éléments de page ont réussi.
+func (c *Checkpoint) MarkDone(page int, now time.Time) {
+	c.FailedPages = slices.DeleteFunc(c.FailedPages, func(p int) bool { return p == page })
+	if page > c.Cursor {
+		c.Cursor = page
+	}
+	c.LastSuccessAt = now
+}
+
+// MarkFailed enregistre qu'au moins un élément de page a échoué : la page sera retentée,
+// mais le curseur avance pour ne pas bloquer les pages suivantes. Une page déjà en échec
+// repasse en fin de liste, pour que les autres pages en échec soient retentées avant elle.
+func (c *Checkpoint) MarkFailed(page int) {
+	c.FailedPages = slices.DeleteFunc(c.FailedPages, func(p int) bool { return p == page })
+	c.FailedPages = append(c.FailedPages, page)
+	if page > c.Cursor {
+		c.Cursor = page
+	}
+	c.LastFailedPage = page
+}
+
+// Drop retire page des pages en échec sans la marquer faite, quand la source ne renvoie plus
+// rien pour elle (ex: une page discover au-delà de la dernière page TMDB). Renvoie true si
+// la page était en échec.
+func (c *Checkpoint) Drop(page int) bool {
+	n := len(c.FailedPages)
+	c.FailedPages = slices.DeleteFunc(c.FailedPages, func(p int) bool { return p == page })
+	return len(c.FailedPages) < n
+}
@@ -0,0 +1,144 @@
+package checkpoint
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		cp   Checkpoint
+		// apply simule un passage : les pages de Pending puis leur résultat
+		apply       func(cp *Checkpoint)
+		wantPending []int
+		wantCursor  int
+		wantFailed  []int
+	}{
+		{
+			name:        "premier passage",
+			cp:          Checkpoint{},
+			wantPending: []int{1},
+		},
+		{
+			name:        "page suivante après une page réussie",
+			cp:          Checkpoint{},
+			apply:       func(cp *Checkpoint) { cp.MarkDone(1, now) },
+			wantPending: []int{2},
+			wantCursor:  1,
+		},
+		{
+			name:        "une page en échec est retentée avant la page suivante",
+			cp:          Checkpoint{Cursor: 3},
+			apply:       func(cp *Checkpoint) { cp.MarkFailed(4) },
+			wantPending: []int{4, 5},
+			wantCursor:  4,
+			wantFailed:  []int{4},
+		},
+		{
+			name:        "une page retentée avec succès sort des échecs",
+			cp:          Checkpoint{Cursor: 5, FailedPages: []int{2, 4}},
+			apply:       func(cp *Checkpoint) { cp.MarkDone(2, now); cp.MarkDone(6, now) },
+			wantPending: []int{4, 7},
+			wantCursor:  6,
+			wantFailed:  []int{4},
+		},
+		{
+			name:        "une page de nouveau en échec passe derrière les autres",
+			cp:          Checkpoint{Cursor: 5, FailedPages: []int{2, 4}},
+			apply:       func(cp *Checkpoint) { cp.MarkFailed(2); cp.MarkDone(6, now) },
+			wantPending: []int{4, 7},
+			wantCursor:  6,
+			wantFailed:  []int{4, 2},
+		},
+		{
+			name:        "le curseur ne recule pas sur un échec ancien",
+			cp:          Checkpoint{Cursor: 9, FailedPages: []int{3}},
+			apply:       func(cp *Checkpoint) { cp.MarkFailed(3) },
+			wantPending: []int{3, 10},
+			wantCursor:  9,
+			wantFailed:  []int{3},
+		},
+		{
+			name:        "une page en échec désormais vide est abandonnée",
+			cp:          Checkpoint{Cursor: 5, FailedPages: []int{5, 2}},
+			apply:       func(cp *Checkpoint) { cp.Drop(5) },
+			wantPending: []int{2, 6},
+			wantCursor:  5,
+			wantFailed:  []int{2},
+		},
+		{
+			name:        "Drop sur une page qui n'est pas en échec ne change rien",
+			cp:          Checkpoint{Cursor: 5},
+			apply:       func(cp *Checkpoint) { cp.Drop(6) },
+			wantPending: []int{6},
+			wantCursor:  5,
+		},
+		{
+			name:        "une page en échec égale à la page suivante n'est lue qu'une fois",
+			cp:          Checkpoint{Cursor: 4, FailedPages: []int{5}},
+			wantPending: []int{5},
+			wantCursor:  4,
+			wantFailed:  []int{5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := tt.cp
+			cp.FailedPages = slices.Clone(cp.FailedPages)
+			if tt.apply != nil {
+				tt.apply(&cp)
+			}
+			if got := cp.Pending(); !slices.Equal(got, tt.wantPending) {
+				t.Errorf("Pending() = %v, attendu %v", got, tt.wantPending)
+			}
+			if cp.Cursor != tt.wantCursor {
+				t.Errorf("Cursor = %d, attendu %d", cp.Cursor, tt.wantCursor)
+			}
+			if !slices.Equal(cp.FailedPages, tt.wantFailed) {
+				t.Errorf("FailedPages = %v, attendu %v", cp.FailedPages, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestCheckpointRotationRetriesEveryFailedPage(t *testing.T) {
+	cp := Checkpoint{Cursor: 10, FailedPages: []int{2, 5, 7}}
+	var retried []int
+	// Trois passages où la page retentée échoue encore : chaque page en échec est retentée
+	for range 3 {
+		page := cp.Pending()[0]
+		retried = append(retried, page)
+		cp.MarkFailed(page)
+	}
+	if want := []int{2, 5, 7}; !slices.Equal(retried, want) {
+		t.Errorf("pages retentées = %v, attendu %v", retried, want)
+	}
+}
+
+func TestDropReportsFailedPage(t *testing.T) {
+	cp := Checkpoint{FailedPages: []int{3}}
+	if !cp.Drop(3) {
+		t.Error("Drop(3) = false pour une page en échec")
+	}
+	if cp.Drop(3) {
+		t.Error("Drop(3) = true pour une page déjà abandonnée")
+	}
+}
+
+func TestMarkDoneSetsLastSuccessAt(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cp := Checkpoint{}
+	cp.MarkFailed(1)
+	if !cp.LastSuccessAt.IsZero() {
+		t.Errorf("LastSuccessAt = %v après un échec", cp.LastSuccessAt)
+	}
+	cp.MarkDone(2, now)
+	if !cp.LastSuccessAt.Equal(now) || cp.LastFailedPage != 1 {
+		t.Errorf("LastSuccessAt = %v, LastFailedPage = %d", cp.LastSuccessAt, cp.LastFailedPage)
+	}
+}
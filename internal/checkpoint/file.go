@@ -0,0 +1,90 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileStore enregistre tous les checkpoints dans un fichier JSON local.
+// Adapté à une seule instance ; l'écriture passe par un fichier temporaire renommé.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore renvoie un FileStore sur path (créé au premier Save).
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Load(ctx context.Context, job string) (Checkpoint, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return Checkpoint{Job: job}, false, err
+	}
+	cp, ok := all[job]
+	if !ok {
+		return Checkpoint{Job: job}, false, nil
+	}
+	cp.Job = job
+	return cp, true, nil
+}
+
+func (s *FileStore) Save(ctx context.Context, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	cp.UpdatedAt = time.Now().UTC()
+	all[cp.Job] = cp
+
+	b, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("checkpoint: encodage JSON: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".checkpoints-*")
+	if err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("checkpoint: écriture: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("checkpoint: écriture: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) read() (map[string]Checkpoint, error) {
+	all := map[string]Checkpoint{}
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint: lecture de %s: %w", s.path, err)
+	}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, fmt.Errorf("checkpoint: décodage de %s: %w", s.path, err)
+	}
+	return all, nil
+}
@@ -0,0 +1,78 @@
+package checkpoint
+
+import (
+	"context"
+	"time"
+
+	"mon-projet/internal/strapi"
+)
+
+// StrapiStore enregistre les checkpoints dans une collection Strapi (une entrée par job),
+// ce qui les rend visibles depuis l'admin et partagés entre instances.
+// Champs attendus : job (uid), cursor, watermark, last_success_at, last_failed_page,
+// failed_pages (json).
+type StrapiStore struct {
+	collection *strapi.Collection[strapiCheckpoint]
+}
+
+type strapiCheckpoint struct {
+	strapi.Entry
+	Job            string     `json:"job"`
+	Cursor         int        `json:"cursor"`
+	Watermark      string     `json:"watermark"`
+	LastSuccessAt  *time.Time `json:"last_success_at"`
+	LastFailedPage int        `json:"last_failed_page"`
+	FailedPages    []int      `json:"failed_pages"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// NewStrapiStore renvoie un StrapiStore sur la collection name (ex: "sync-checkpoints").
+func NewStrapiStore(client *strapi.Client, name string) *StrapiStore {
+	return &StrapiStore{collection: strapi.NewCollection[strapiCheckpoint](client, name)}
+}
+
+func (s *StrapiStore) Load(ctx context.Context, job string) (Checkpoint, bool, error) {
+	doc, err := s.collection.First(ctx, strapi.NewQuery().Eq("job", job))
+	if err != nil || doc == nil {
+		return Checkpoint{Job: job}, false, err
+	}
+	cp := Checkpoint{
+		Job:            job,
+		Cursor:         doc.Cursor,
+		Watermark:      doc.Watermark,
+		LastFailedPage: doc.LastFailedPage,
+		FailedPages:    doc.FailedPages,
+		UpdatedAt:      doc.UpdatedAt,
+	}
+	if doc.LastSuccessAt != nil {
+		cp.LastSuccessAt = *doc.LastSuccessAt
+	}
+	return cp, true, nil
+}
+
+func (s *StrapiStore) Save(ctx context.Context, cp Checkpoint) error {
+	data := map[string]interface{}{
+		"job":              cp.Job,
+		"cursor":           cp.Cursor,
+		"watermark":        cp.Watermark,
+		"last_failed_page": cp.LastFailedPage,
+		"failed_pages":     cp.FailedPages,
+	}
+	if cp.FailedPages == nil {
+		data["failed_pages"] = []int{}
+	}
+	if !cp.LastSuccessAt.IsZero() {
+		data["last_success_at"] = cp.LastSuccessAt.UTC().Format(time.RFC3339)
+	}
+
+	doc, err := s.collection.First(ctx, strapi.NewQuery().Eq("job", cp.Job))
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		_, err = s.collection.Create(ctx, data)
+		return err
+	}
+	_, err = s.collection.Update(ctx, doc.Key(), data)
+	return err
+}
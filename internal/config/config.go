@@ -0,0 +1,251 @@
+// Package config charge la configuration des synchronisations depuis un fichier JSON
+// (SYNC_CONFIG, par défaut sync.json). Le fichier est optionnel : sans lui chaque job
+// garde son comportement historique.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPath est le fichier lu quand SYNC_CONFIG n'est pas renseigné.
+const DefaultPath = "sync.json"
+
+// DefaultPipeline est le nom du pipeline utilisé quand un job n'en déclare aucun.
+const DefaultPipeline = "default"
+
+// Modes d'écriture des traductions dans Strapi.
+const (
+	// LocaleModeFields écrit chaque locale secondaire dans des champs suffixés (ex: title_en_us).
+	LocaleModeFields = "fields"
+	// LocaleModeI18n écrit chaque locale secondaire comme localisation Strapi (plugin i18n).
+	LocaleModeI18n = "i18n"
+)
+
+// Config regroupe la configuration de tous les jobs.
+type Config struct {
+	// Locales à ingérer ; la première est la locale principale (champs sans suffixe).
+	// Vide : la langue du client TMDB (TMDB_LANGUAGE, fr-FR par défaut).
+	Locales []string `json:"locales"`
+	// LocaleMode vaut LocaleModeFields (défaut) ou LocaleModeI18n.
+	LocaleMode string      `json:"locale_mode"`
+	Movies     DiscoverJob `json:"movies"`
+	TvShows    DiscoverJob `json:"tv_shows"`
+	// Enrichment règle le rythme des appels de détail (/movie/{id}, /tv/{id}…).
+	Enrichment Enrichment `json:"enrichment"`
+	// Regions sont les pays (ISO 3166-1, ex: "FR") pour lesquels on enregistre
+	// la disponibilité en streaming et les classifications d'âge. Vide : FR et US.
+	Regions []string `json:"regions"`
+	// Lists règle la synchronisation des listes TMDB (tendances, à l'affiche…).
+	Lists Lists `json:"lists"`
+	// Schedules remplace l'expression cron d'un job par son nom (ex: "movies": "*/30 * * * *").
+	// "off" désactive la planification. La variable SCHEDULE_<NOM> reste prioritaire.
+	Schedules map[string]string `json:"schedules"`
+}
+
+// Lists configure SyncTitleLists.
+type Lists struct {
+	// Pages est le nombre de pages TMDB (20 titres chacune) lues par liste. Défaut : 1.
+	Pages int `json:"pages"`
+}
+
+// Enrichment découpe les appels de détail TMDB en lots pour respecter la limite de débit.
+type Enrichment struct {
+	// BatchSize est le nombre de titres enrichis entre deux pauses.
+	BatchSize int `json:"batch_size"`
+	// BatchPause est la pause entre deux lots (ex: "1s").
+	BatchPause Duration `json:"batch_pause"`
+	// PageSize est le nombre de documents Strapi lus par page par les jobs qui parcourent
+	// le catalogue (crédits, vidéos, mots-clés…). Défaut : 50. Le curseur de ces jobs est un
+	// numéro de page : après un changement, remettre leurs checkpoints à zéro.
+	PageSize int `json:"page_size"`
+	// PagesPerRun borne le nombre de pages traitées par passage de ces jobs. Défaut : 20 ;
+	// une valeur négative retire la limite (le passage s'arrête alors sur MaxRunTime).
+	PagesPerRun int `json:"pages_per_run"`
+	// MaxRunTime borne la durée d'un passage (ex: "30m") : la page en cours est terminée,
+	// le passage suivant reprend à la page d'après. Défaut : 30m.
+	MaxRunTime Duration `json:"max_run_time"`
+}
+
+// Duration est une time.Duration lue depuis une chaîne JSON (ex: "1s", "500ms").
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("durée attendue sous forme de chaîne (ex: \"1s\"): %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// DiscoverJob configure SyncMovies ou SyncTvShows : une liste de pipelines discover,
+// chacun avec son propre checkpoint.
+type DiscoverJob struct {
+	Pipelines []Pipeline `json:"pipelines"`
+}
+
+// Pipeline est une requête discover nommée.
+type Pipeline struct {
+	Name     string   `json:"name"`
+	Discover Discover `json:"discover"`
+}
+
+// Discover reprend les paramètres discover/movie et discover/tv de TMDB.
+// release_date.* est traduit en primary_release_date.* (films) ou first_air_date.* (séries).
+type Discover struct {
+	SortBy               string `json:"sort_by"`
+	ReleaseDateGTE       string `json:"release_date.gte"`
+	ReleaseDateLTE       string `json:"release_date.lte"`
+	WithGenres           string `json:"with_genres"`
+	WithOriginalLanguage string `json:"with_original_language"`
+	VoteCountGTE         int    `json:"vote_count.gte"`
+	IncludeAdult         *bool  `json:"include_adult"`
+	Region               string `json:"region"`
+	WithWatchProviders   string `json:"with_watch_providers"`
+	WatchRegion          string `json:"watch_region"`
+	// Extra permet de passer tout autre paramètre discover tel quel.
+	Extra map[string]string `json:"extra"`
+}
+
+// Params renvoie les paramètres de la requête discover pour mediaType ("movie" ou "tv").
+func (d Discover) Params(mediaType string) url.Values {
+	dateField := "primary_release_date"
+	if mediaType == "tv" {
+		dateField = "first_air_date"
+	}
+
+	params := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	set("sort_by", d.SortBy)
+	set(dateField+".gte", d.ReleaseDateGTE)
+	set(dateField+".lte", d.ReleaseDateLTE)
+	set("with_genres", d.WithGenres)
+	set("with_original_language", d.WithOriginalLanguage)
+	if d.VoteCountGTE > 0 {
+		set("vote_count.gte", strconv.Itoa(d.VoteCountGTE))
+	}
+	if d.IncludeAdult != nil {
+		set("include_adult", strconv.FormatBool(*d.IncludeAdult))
+	}
+	if mediaType != "tv" {
+		set("region", d.Region)
+	}
+	set("with_watch_providers", d.WithWatchProviders)
+	set("watch_region", d.WatchRegion)
+	for k, v := range d.Extra {
+		set(k, v)
+	}
+	return params
+}
+
+// Load lit path. Un fichier absent n'est pas une erreur : la configuration par défaut est renvoyée.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("config: lecture de %s: %w", path, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, cfg); err != nil {
+			return nil, fmt.Errorf("config: décodage de %s: %w", path, err)
+		}
+	}
+	cfg.applyDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// LoadFromEnv lit le fichier désigné par SYNC_CONFIG (ou DefaultPath).
+func LoadFromEnv() (*Config, error) {
+	path := os.Getenv("SYNC_CONFIG")
+	if path == "" {
+		path = DefaultPath
+	}
+	return Load(path)
+}
+
+func (c *Config) applyDefaults() {
+	if c.LocaleMode == "" {
+		c.LocaleMode = LocaleModeFields
+	}
+	if c.Enrichment.BatchSize <= 0 {
+		c.Enrichment.BatchSize = 10
+	}
+	if c.Enrichment.BatchPause <= 0 {
+		c.Enrichment.BatchPause = Duration(time.Second)
+	}
+	if c.Enrichment.PageSize <= 0 {
+		c.Enrichment.PageSize = 50
+	}
+	if c.Enrichment.PagesPerRun == 0 {
+		c.Enrichment.PagesPerRun = 20
+	}
+	if c.Enrichment.MaxRunTime <= 0 {
+		c.Enrichment.MaxRunTime = Duration(30 * time.Minute)
+	}
+	if c.Lists.Pages <= 0 {
+		c.Lists.Pages = 1
+	}
+	if len(c.Regions) == 0 {
+		c.Regions = []string{"FR", "US"}
+	}
+	for _, job := range []*DiscoverJob{&c.Movies, &c.TvShows} {
+		if len(job.Pipelines) == 0 {
+			job.Pipelines = []Pipeline{{Name: DefaultPipeline}}
+		}
+	}
+}
+
+func (c *Config) validate() error {
+	if c.LocaleMode != LocaleModeFields && c.LocaleMode != LocaleModeI18n {
+		return fmt.Errorf("locale_mode %q inconnu (attendu %q ou %q)", c.LocaleMode, LocaleModeFields, LocaleModeI18n)
+	}
+	seenLocales := map[string]bool{}
+	for _, l := range c.Locales {
+		if l == "" || seenLocales[l] {
+			return fmt.Errorf("locales: %q vide ou déclarée deux fois", l)
+		}
+		seenLocales[l] = true
+	}
+	seenRegions := map[string]bool{}
+	for _, r := range c.Regions {
+		if len(r) != 2 || r != strings.ToUpper(r) || seenRegions[r] {
+			return fmt.Errorf("regions: %q n'est pas un code pays ISO 3166-1 (ex: \"FR\") ou est déclarée deux fois", r)
+		}
+		seenRegions[r] = true
+	}
+	for jobName, job := range map[string]DiscoverJob{"movies": c.Movies, "tv_shows": c.TvShows} {
+		seen := map[string]bool{}
+		for _, p := range job.Pipelines {
+			if p.Name == "" {
+				return fmt.Errorf("%s: un pipeline n'a pas de nom", jobName)
+			}
+			if seen[p.Name] {
+				return fmt.Errorf("%s: pipeline %q déclaré deux fois", jobName, p.Name)
+			}
+			seen[p.Name] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// strapiCertifications est la collection de référence des classifications d'âge,
+// unique par (media_type, region, certification)
+const strapiCertifications = "certifications"
+
+// requiredCertificationRegions sont toujours synchronisées, en plus de regions
+var requiredCertificationRegions = []string{"FR", "US"}
+
+// certificationRegions renvoie les pays dont on enregistre les classifications :
+// FR, US et ceux de regions
+func certificationRegions() []string {
+	regions := slices.Clone(requiredCertificationRegions)
+	for _, r := range syncConfig.Regions {
+		if !slices.Contains(regions, r) {
+			regions = append(regions, r)
+		}
+	}
+	return regions
+}
+
+// SyncCertifications parcourt les titres stockés (checkpoints certifications:movie et
+// certifications:tv) et écrit sur chacun sa classification par pays (certification_fr,
+// certification_us…) ainsi que, pour les films, ses dates de sortie (release_dates)
+func SyncCertifications() {
+	ctx := context.Background()
+	syncStoredTitles(ctx, "certifications:movie", tmdb.MediaMovie, syncConfig.Enrichment.PagesPerRun, syncTitleCertifications)
+	syncStoredTitles(ctx, "certifications:tv", tmdb.MediaTV, syncConfig.Enrichment.PagesPerRun, syncTitleCertifications)
+}
+
+func syncTitleCertifications(ctx context.Context, t storedTitle) error {
+	regions := certificationRegions()
+	payload := map[string]interface{}{}
+	for _, region := range regions {
+		payload[certificationField(region)] = ""
+	}
+
+	if t.mediaType == tmdb.MediaTV {
+		ratings, err := tmdbClient.ContentRatings(ctx, t.tmdbID)
+		if err != nil {
+			return fmt.Errorf("classifications TMDB: %w", err)
+		}
+		for _, r := range ratings.Results {
+			if slices.Contains(regions, r.ISO3166_1) {
+				payload[certificationField(r.ISO3166_1)] = r.Rating
+			}
+		}
+	} else {
+		dates, err := tmdbClient.ReleaseDates(ctx, t.tmdbID)
+		if err != nil {
+			return fmt.Errorf("dates de sortie TMDB: %w", err)
+		}
+		releases := map[string][]tmdb.ReleaseDate{}
+		for _, r := range dates.Results {
+			if !slices.Contains(regions, r.ISO3166_1) {
+				continue
+			}
+			releases[r.ISO3166_1] = r.ReleaseDates
+			payload[certificationField(r.ISO3166_1)] = movieCertification(r.ReleaseDates)
+		}
+		payload["release_dates"] = releases
+	}
+
+	name, _ := mediaCollection(t.mediaType)
+	if _, _, err := updateDocument(ctx, name, t.doc, payload); err != nil {
+		return fmt.Errorf("classifications Strapi: %w", err)
+	}
+	return nil
+}
+
+// certificationField renvoie le champ de classification d'un pays (FR → certification_fr)
+func certificationField(region string) string {
+	return "certification_" + strings.ToLower(region)
+}
+
+// movieCertification choisit la classification d'un film dans un pays : celle de la sortie
+// en salles si elle est renseignée, sinon celle de la première sortie qui en a une
+func movieCertification(releases []tmdb.ReleaseDate) string {
+	sorted := slices.Clone(releases)
+	slices.SortStableFunc(sorted, func(a, b tmdb.ReleaseDate) int {
+		return releasePriority(a.Type) - releasePriority(b.Type)
+	})
+	for _, r := range sorted {
+		if r.Certification != "" {
+			return r.Certification
+		}
+	}
+	return ""
+}
+
+func releasePriority(releaseType int) int {
+	if releaseType == tmdb.ReleaseTheatrical {
+		return 0
+	}
+	return releaseType
+}
+
+// SyncCertificationList met à jour la référence des classifications d'âge des pays
+// synchronisés depuis /certification/movie/list et /certification/tv/list, et supprime
+// celles que TMDB ne renvoie plus
+func SyncCertificationList() {
+	ctx := context.Background()
+
+	stored, err := collection[strapi.Document](strapiCertifications).All(ctx, strapi.NewQuery())
+	if err != nil {
+		log.Printf("❌ Lecture des classifications Strapi: %v", err)
+		return
+	}
+	byKey := make(map[string]strapi.Document, len(stored))
+	for _, doc := range stored {
+		byKey[certificationKey(toString(doc["media_type"]), toString(doc["region"]), toString(doc["certification"]))] = doc
+	}
+
+	var stats SyncStats
+	for _, mediaType := range []string{tmdb.MediaMovie, tmdb.MediaTV} {
+		list, err := tmdbClient.Certifications(ctx, mediaType)
+		if err != nil {
+			// Sans la liste complète on ne peut pas savoir ce qui a disparu
+			log.Printf("❌ Erreur TMDB certification/%s/list: %v", mediaType, err)
+			return
+		}
+		for _, region := range certificationRegions() {
+			for _, c := range list[region] {
+				payload := map[string]interface{}{
+					"media_type":    mediaType,
+					"region":        region,
+					"certification": c.Certification,
+					"meaning":       c.Meaning,
+					"order":         c.Order,
+				}
+				key := certificationKey(mediaType, region, c.Certification)
+				var res upsertResult
+				if doc, ok := byKey[key]; ok {
+					delete(byKey, key)
+					res, _, err = updateDocument(ctx, strapiCertifications, doc, payload)
+				} else {
+					res = upsertInserted
+					_, err = collection[strapi.Document](strapiCertifications).Create(ctx, payload)
+				}
+				if err != nil {
+					log.Printf("❌ Classification %s: %v", key, err)
+					stats.Failed++
+					continue
+				}
+				stats.add(res)
+			}
+		}
+	}
+
+	removed := 0
+	for key, doc := range byKey {
+		if err := collection[strapi.Document](strapiCertifications).Delete(ctx, doc.DocumentID()); err != nil {
+			log.Printf("❌ Suppression de la classification %s: %v", key, err)
+			continue
+		}
+		removed++
+	}
+	log.Printf("📊 Classifications : %s, %d supprimées", stats, removed)
+}
+
+func certificationKey(mediaType, region, certification string) string {
+	return mediaType + ":" + region + ":" + certification
+}
+
+func CertificationsHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "certifications", "Synchronisation des classifications d'âge des titres déclenchée")
+}
+
+func CertificationListHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "certification-list", "Synchronisation de la liste des classifications d'âge déclenchée")
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// changesDateLayout est le format attendu par start_date / end_date
+const changesDateLayout = "2006-01-02"
+
+// SyncChanges relit /movie/changes et /tv/changes depuis le dernier passage
+// (watermark du checkpoint "changes"),
+// puis met à jour les films et séries modifiés qui existent déjà dans Strapi.
+// Le watermark n'avance que si tous les titres modifiés ont été traités.
+func SyncChanges() {
+	ctx := context.Background()
+
+	cp, err := loadCheckpoint(ctx, "changes", nil)
+	if err != nil {
+		log.Printf("❌ Lecture du checkpoint changes: %v", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	end := now.Format(changesDateLayout)
+	start := cp.Watermark
+	if start == "" {
+		start = now.AddDate(0, 0, -1).Format(changesDateLayout)
+	}
+	// TMDB refuse un intervalle de plus de 14 jours : on rattrape au mieux
+	if t, err := time.Parse(changesDateLayout, start); err != nil || now.Sub(t) > tmdb.MaxChangesRange {
+		start = now.Add(-tmdb.MaxChangesRange).Format(changesDateLayout)
+		log.Printf("⚠️ Watermark trop ancien ou invalide, reprise au %s", start)
+	}
+	log.Printf("🔄 Sync Changes : modifications TMDB du %s au %s", start, end)
+
+	moviesOK := syncChangedTitles(ctx, tmdb.MediaMovie, start, end)
+	tvOK := syncChangedTitles(ctx, tmdb.MediaTV, start, end)
+
+	if !moviesOK || !tvOK {
+		log.Printf("⚠️ Des modifications n'ont pas été appliquées, le watermark reste au %s", start)
+		return
+	}
+	cp.Watermark = end
+	cp.LastSuccessAt = now
+	saveCheckpoint(ctx, cp)
+	log.Printf("✅ Sync Changes terminée, prochain départ au %s", end)
+}
+
+// syncChangedTitles applique les modifications d'un type de média et renvoie false si un titre a échoué
+func syncChangedTitles(ctx context.Context, mediaType, start, end string) bool {
+	var ids []int
+	for page := 1; ; page++ {
+		cp, err := tmdbClient.Changes(ctx, mediaType, start, end, page)
+		if err != nil {
+			log.Printf("❌ Erreur TMDB %s/changes (page %d): %v", mediaType, page, err)
+			return false
+		}
+		for _, c := range cp.Results {
+			ids = append(ids, c.ID)
+		}
+		if page >= cp.TotalPages {
+			break
+		}
+	}
+
+	name, field := mediaCollection(mediaType)
+
+	stored, err := findManyByTMDBID(ctx, name, field, ids)
+	if err != nil {
+		log.Printf("❌ Erreur lecture Strapi %s: %v", name, err)
+		return false
+	}
+	log.Printf("📦 %s/changes : %d identifiants modifiés, %d présents dans Strapi", mediaType, len(ids), len(stored))
+
+	storedIDs := make([]int, 0, len(stored))
+	for id := range stored {
+		storedIDs = append(storedIDs, id)
+	}
+
+	// Les détails sont relus par lots pour respecter la limite de débit TMDB
+	ok := true
+	var stats SyncStats
+	runBatched(ctx, storedIDs, func(id int) error {
+		var res upsertResult
+		var err error
+		if mediaType == tmdb.MediaTV {
+			var details *tmdb.TVDetails
+			var doc strapi.Document
+			var rel titleRelations
+			if details, err = tmdbClient.TVDetails(ctx, id, tvDetailsAppend...); err == nil {
+				rel.Translations = details.Translations
+				if rel.Keywords, err = resolveKeywords(ctx, detailsKeywords(details.Keywords)); err == nil {
+					if res, doc, err = upsertTvShow(ctx, details.AsTVShow(), 0, rel); err == nil {
+						err = enrichTvShow(ctx, doc, details)
+					}
+				}
+			}
+		} else {
+			var details *tmdb.MovieDetails
+			var doc strapi.Document
+			var rel titleRelations
+			if details, err = tmdbClient.MovieDetails(ctx, id, movieDetailsAppend...); err == nil {
+				rel.Translations = details.Translations
+				if rel.Keywords, err = resolveKeywords(ctx, detailsKeywords(details.Keywords)); err == nil {
+					if res, doc, err = upsertFilm(ctx, details.AsMovie(), 0, rel); err == nil {
+						err = enrichFilm(ctx, doc, details)
+					}
+				}
+			}
+		}
+		if err != nil {
+			// Un titre supprimé chez TMDB (404) ne doit pas bloquer le watermark
+			ok = ok && tmdb.IsNotFound(err)
+			stats.Failed++
+			return fmt.Errorf("mise à jour %s %d: %w", name, id, err)
+		}
+		stats.add(res)
+		return nil
+	})
+
+	log.Printf("📊 %s/changes : %s", mediaType, stats)
+	return ok
+}
+
+func ChangesHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "changes", "Synchronisation incrémentale (changes) déclenchée")
+}
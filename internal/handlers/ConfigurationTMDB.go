@@ -1,25 +1,27 @@
 package handlers
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"os"
 	"reflect"
 
-	"github.com/joho/godotenv"
-	cron "github.com/robfig/cron/v3"
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
 )
 
-var (
-	tmdbConfigurationURL   string
-	strapiConfigurationURL string
-)
+// strapiConfigurations est la collection Strapi qui porte la configuration TMDB
+const strapiConfigurations = "configurations"
+
+type TmdbImageConfig = tmdb.ImageConfig
+
+type TmdbConfigResponse = tmdb.Configuration
 
-type TmdbImageConfig struct {
+// configurationStrapi reprend une entrée de la collection configurations,
+// avec les champs à la racine de chaque data[]
+type configurationStrapi struct {
+	strapi.Entry
 	BaseURL       string   `json:"base_url"`
 	SecureBaseURL string   `json:"secure_base_url"`
 	BackdropSizes []string `json:"backdrop_sizes"`
@@ -27,127 +29,51 @@ type TmdbImageConfig struct {
 	PosterSizes   []string `json:"poster_sizes"`
 	ProfileSizes  []string `json:"profile_sizes"`
 	StillSizes    []string `json:"still_sizes"`
-}
-
-type TmdbConfigResponse struct {
-	Images     TmdbImageConfig `json:"images"`
-	ChangeKeys []string        `json:"change_keys"`
-}
-
-func init() {
-	// Charge .env avant d'utiliser os.Getenv
-	if err := godotenv.Load(); err != nil {
-		log.Printf("⚠️ .env non chargé: %v", err)
-	}
-
-	tmdbConfigurationURL = "https://api.themoviedb.org/3/configuration"
-	strapiConfigurationURL = os.Getenv("STRAPI_URL") + "/api/configurations"
-
-	c := cron.New()
-	_, err := c.AddFunc("0 0 * * 0", func() {
-		log.Println("🚀 Lancement planifié: SyncConfiguration chaque une semaine ")
-		SyncConfiguration()
-	})
-	if err != nil {
-		log.Fatalf("Erreur planification cron: %v", err)
-	}
-	c.Start()
+	ChangeKeys    []string `json:"change_keys"`
 }
 
 func SyncConfiguration() {
+	ctx := context.Background()
+
 	// Étape 1: récupère la config TMDB
-	tmdbURL := fmt.Sprintf("%s?api_key=%s", tmdbConfigurationURL, os.Getenv("API_KEY"))
-	tmdbRespRaw, err := http.Get(tmdbURL)
+	tmdbResp, err := tmdbClient.Configuration(ctx)
 	if err != nil {
 		log.Printf("⚠️ Erreur fetch configuration TMDB: %v", err)
 		return
 	}
-	defer tmdbRespRaw.Body.Close()
-
-	var tmdbResp TmdbConfigResponse
-	if err := json.NewDecoder(tmdbRespRaw.Body).Decode(&tmdbResp); err != nil {
-		log.Printf("⚠️ Erreur décodage JSON TMDB: %v", err)
-		return
-	}
 
 	// Étape 2: récupère la config Strapi
-	req, err := http.NewRequest("GET", strapiConfigurationURL, nil)
-	if err != nil {
-		log.Printf("⚠️ Erreur création requête GET Strapi: %v", err)
-		return
-	}
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("STRAPI_TOKEN"))
-
-	resp, err := http.DefaultClient.Do(req)
+	configurations := collection[configurationStrapi](strapiConfigurations)
+	entry, err := configurations.First(ctx, nil)
 	if err != nil {
 		log.Printf("⚠️ Erreur récupération configuration Strapi: %v", err)
 		return
 	}
-	defer resp.Body.Close()
-
-	// Vérifie le status
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("⚠️ GET config Strapi returned %d: %s", resp.StatusCode, string(body))
-		return
-	}
 
-	// Décodage avec les champs à la racine de chaque data[]
-	var strapiResponse struct {
-		Data []struct {
-			ID            string   `json:"documentId"`
-			BaseURL       string   `json:"base_url"`
-			SecureBaseURL string   `json:"secure_base_url"`
-			BackdropSizes []string `json:"backdrop_sizes"`
-			LogoSizes     []string `json:"logo_sizes"`
-			PosterSizes   []string `json:"poster_sizes"`
-			ProfileSizes  []string `json:"profile_sizes"`
-			StillSizes    []string `json:"still_sizes"`
-			ChangeKeys    []string `json:"change_keys"`
-		} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&strapiResponse); err != nil {
-		log.Printf("⚠️ Erreur décodage configuration Strapi: %v", err)
-		return
+	payload := map[string]interface{}{
+		"base_url":        tmdbResp.Images.BaseURL,
+		"secure_base_url": tmdbResp.Images.SecureBaseURL,
+		"backdrop_sizes":  tmdbResp.Images.BackdropSizes,
+		"logo_sizes":      tmdbResp.Images.LogoSizes,
+		"poster_sizes":    tmdbResp.Images.PosterSizes,
+		"profile_sizes":   tmdbResp.Images.ProfileSizes,
+		"still_sizes":     tmdbResp.Images.StillSizes,
+		"change_keys":     tmdbResp.ChangeKeys,
 	}
 
 	// Si aucune entrée, on POST
-	if len(strapiResponse.Data) == 0 {
+	if entry == nil {
 		log.Println(" Aucune configuration trouvée, création via POST")
-		payload := map[string]interface{}{
-			"data": map[string]interface{}{
-				"base_url":        tmdbResp.Images.BaseURL,
-				"secure_base_url": tmdbResp.Images.SecureBaseURL,
-				"backdrop_sizes":  tmdbResp.Images.BackdropSizes,
-				"logo_sizes":      tmdbResp.Images.LogoSizes,
-				"poster_sizes":    tmdbResp.Images.PosterSizes,
-				"profile_sizes":   tmdbResp.Images.ProfileSizes,
-				"still_sizes":     tmdbResp.Images.StillSizes,
-				"change_keys":     tmdbResp.ChangeKeys,
-			},
-		}
-		body, _ := json.Marshal(payload)
-		postReq, _ := http.NewRequest("POST", strapiConfigurationURL, bytes.NewReader(body))
-		postReq.Header.Set("Content-Type", "application/json")
-		postReq.Header.Set("Authorization", "Bearer "+os.Getenv("STRAPI_TOKEN"))
-
-		postRes, err := http.DefaultClient.Do(postReq)
-		if err != nil {
-			log.Printf("⚠️ Erreur exécution POST: %v", err)
+		if _, err := configurations.Create(ctx, payload); err != nil {
+			log.Printf("⚠️ POST échoué: %v", err)
 			return
 		}
-		defer postRes.Body.Close()
-		if postRes.StatusCode >= 200 && postRes.StatusCode < 300 {
-			log.Println("✅ Configuration créée avec succès via POST")
-		} else {
-			log.Printf("⚠️ POST échoué - Code: %d", postRes.StatusCode)
-		}
+		log.Println("✅ Configuration créée avec succès via POST")
 		return
 	}
 
 	// Reçoit la première entrée existante
-	entry := strapiResponse.Data[0]
-	strapiID := entry.ID
+	strapiID := entry.Key()
 	strapiConfig := TmdbImageConfig{
 		BaseURL:       entry.BaseURL,
 		SecureBaseURL: entry.SecureBaseURL,
@@ -174,43 +100,16 @@ func SyncConfiguration() {
 
 	log.Println("⚠️ Différence détectée, on va mettre à jour…")
 
-	// Étape 4: Construction du payload pour PUT
-	payload := map[string]interface{}{
-		"data": map[string]interface{}{
-			"base_url":        tmdbResp.Images.BaseURL,
-			"secure_base_url": tmdbResp.Images.SecureBaseURL,
-			"backdrop_sizes":  tmdbResp.Images.BackdropSizes,
-			"logo_sizes":      tmdbResp.Images.LogoSizes,
-			"poster_sizes":    tmdbResp.Images.PosterSizes,
-			"profile_sizes":   tmdbResp.Images.ProfileSizes,
-			"still_sizes":     tmdbResp.Images.StillSizes,
-			"change_keys":     tmdbResp.ChangeKeys,
-		},
-	}
-	configJSON, _ := json.Marshal(payload)
-
-	log.Printf("➡️ Tentative PUT sur %s/%s", strapiConfigurationURL, strapiID)
-	putReq, _ := http.NewRequest("PUT", fmt.Sprintf("%s/%s", strapiConfigurationURL, strapiID), bytes.NewReader(configJSON))
-	putReq.Header.Set("Content-Type", "application/json")
-	putReq.Header.Set("Authorization", "Bearer "+os.Getenv("STRAPI_TOKEN"))
-
-	putRes, err := http.DefaultClient.Do(putReq)
-	if err != nil {
-		log.Printf("⚠️ Erreur exécution PUT: %v", err)
+	// Étape 4: PUT du payload
+	log.Printf("➡️ Tentative PUT sur %s/%s", strapiConfigurations, strapiID)
+	if _, err := configurations.Update(ctx, strapiID, payload); err != nil {
+		log.Printf("⚠️ PUT échoué: %v", err)
 		return
 	}
-	defer putRes.Body.Close()
-
-	if putRes.StatusCode >= 200 && putRes.StatusCode < 300 {
-		log.Println("🔄 Configuration mise à jour avec succès")
-	} else {
-		log.Printf("⚠️ PUT échoué - Code: %d", putRes.StatusCode)
-	}
+	log.Println("🔄 Configuration mise à jour avec succès")
 
 }
 
 func ConfigurationHandler(w http.ResponseWriter, r *http.Request) {
-	go SyncConfiguration()
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "Synchronisation de la configuration déclenchée")
+	triggerJob(w, "configuration", "Synchronisation de la configuration déclenchée")
 }
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// strapiFilmCollections est la collection Strapi des sagas TMDB (belongs_to_collection).
+// Sa relation films relie les films de la saga déjà stockés, dans l'ordre de sortie.
+const strapiFilmCollections = "film-collections"
+
+// collectionRefreshDelay évite de relire une saga sur TMDB à chaque film enrichi qui en fait partie
+const collectionRefreshDelay = 6 * time.Hour
+
+// SyncFilmCollections parcourt toutes les sagas stockées à chaque passage (checkpoint
+// film-collections) pour mettre à jour leurs films : une saga est créée lors de l'enrichissement
+// d'un de ses films (voir enrichFilm)
+func SyncFilmCollections() {
+	walkCollection(context.Background(), "film-collections", strapiFilmCollections, allPages, func(ctx context.Context, doc strapi.Document) error {
+		id, err := strconv.Atoi(toString(doc["id_collection"]))
+		if err != nil {
+			return fmt.Errorf("id_collection invalide sur le document %s", doc.DocumentID())
+		}
+		return syncFilmCollection(ctx, id)
+	})
+}
+
+// syncedCollection est une saga relue sur TMDB par ce processus
+type syncedCollection struct {
+	at time.Time
+	// parts sont les identifiants TMDB de ses films, dans l'ordre de sortie
+	parts []int
+}
+
+// collectionsSynced retient les sagas relues par ce processus
+var collectionsSynced struct {
+	sync.Mutex
+	byID map[int]syncedCollection
+}
+
+// syncFilmCollectionOnce synchronise la saga collectionID. Si elle a été relue sur TMDB il y a
+// moins de collectionRefreshDelay (plusieurs films d'une même saga sont souvent enrichis
+// ensemble), seule sa relation films est mise à jour, pour y ajouter les films stockés depuis.
+func syncFilmCollectionOnce(ctx context.Context, collectionID int) error {
+	collectionsSynced.Lock()
+	synced, ok := collectionsSynced.byID[collectionID]
+	collectionsSynced.Unlock()
+	if !ok || time.Since(synced.at) >= collectionRefreshDelay {
+		return syncFilmCollection(ctx, collectionID)
+	}
+
+	stored, err := findByTMDBID(ctx, strapiFilmCollections, "id_collection", collectionID, "films")
+	if err != nil {
+		return fmt.Errorf("saga %d: %w", collectionID, err)
+	}
+	if stored == nil {
+		return syncFilmCollection(ctx, collectionID)
+	}
+	films, err := collectionFilms(ctx, collectionID, synced.parts)
+	if err != nil {
+		return err
+	}
+	if _, _, err := updateDocument(ctx, strapiFilmCollections, stored, map[string]interface{}{"films": films}); err != nil {
+		return fmt.Errorf("saga %d: %w", collectionID, err)
+	}
+	return nil
+}
+
+// collectionFilms renvoie la relation films d'une saga : ceux de ses films (parts, dans l'ordre
+// de sortie) déjà stockés
+func collectionFilms(ctx context.Context, collectionID int, parts []int) (relationSet, error) {
+	stored, err := findManyByTMDBID(ctx, strapiFilms, "id_film", parts, "id_film")
+	if err != nil {
+		return nil, fmt.Errorf("lecture des films de la saga %d: %w", collectionID, err)
+	}
+	films := relationSet{}
+	for _, id := range parts {
+		if doc, ok := stored[id]; ok {
+			films = append(films, doc.DocumentID())
+		}
+	}
+	return films, nil
+}
+
+// syncFilmCollection enregistre la saga collectionID (upsert par id_collection) avec ses
+// traductions, la liste ordonnée de ses films (parts) et la relation vers ceux déjà stockés
+func syncFilmCollection(ctx context.Context, collectionID int) error {
+	c, err := tmdbClient.Collection(ctx, collectionID)
+	if err != nil {
+		return fmt.Errorf("saga TMDB %d: %w", collectionID, err)
+	}
+
+	// Ordre de sortie, les films sans date en dernier
+	parts := slices.Clone(c.Parts)
+	slices.SortStableFunc(parts, func(a, b tmdb.Movie) int {
+		switch {
+		case a.ReleaseDate == b.ReleaseDate:
+			return 0
+		case a.ReleaseDate == "":
+			return 1
+		case b.ReleaseDate == "":
+			return -1
+		case a.ReleaseDate < b.ReleaseDate:
+			return -1
+		}
+		return 1
+	})
+
+	ids := make([]int, 0, len(parts))
+	partList := make([]map[string]interface{}, 0, len(parts))
+	for i, p := range parts {
+		ids = append(ids, p.ID)
+		partList = append(partList, map[string]interface{}{
+			"order":        i + 1,
+			"id_film":      p.ID,
+			"title":        p.Title,
+			"release_date": p.ReleaseDate,
+			"poster_path":  p.PosterPath,
+		})
+	}
+	films, err := collectionFilms(ctx, collectionID, ids)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"id_collection": c.ID,
+		"name":          c.Name,
+		"overview":      c.Overview,
+		"poster_path":   c.PosterPath,
+		"backdrop_path": c.BackdropPath,
+		"parts":         partList,
+		"films":         films,
+	}
+	res, doc, err := upsertByTMDBID(ctx, strapiFilmCollections, "id_collection", c.ID, payload)
+	if err != nil {
+		return fmt.Errorf("saga %d: %w", collectionID, err)
+	}
+	_, err = upsertTitleTranslations(ctx, strapiFilmCollections, "name", res, doc, nil, func() (map[string]titleTranslation, error) {
+		return fetchTitleTranslations(ctx, tmdb.MediaCollection, c.ID, c.Name, c.Overview)
+	})
+	if err != nil {
+		return fmt.Errorf("saga %d: %w", collectionID, err)
+	}
+
+	collectionsSynced.Lock()
+	if collectionsSynced.byID == nil {
+		collectionsSynced.byID = map[int]syncedCollection{}
+	}
+	collectionsSynced.byID[collectionID] = syncedCollection{at: time.Now(), parts: ids}
+	collectionsSynced.Unlock()
+
+	if res != upsertUnchanged {
+		log.Printf("📚 Saga %s (%d) : %d films dont %d stockés", c.Name, c.ID, len(parts), len(films))
+	}
+	return nil
+}
+
+func FilmCollectionsHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "film-collections", "Synchronisation des sagas de films déclenchée")
+}
@@ -1,144 +1,282 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
+	"strconv"
+	"sync"
 
-	"io"
-
-	"github.com/joho/godotenv"
-	cron "github.com/robfig/cron/v3"
+	"mon-projet/internal/config"
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
 )
 
-var (
-	tmdbMovieGenreURL string
-	tmdbTvGenreURL    string
-	strapiTvURL       string
-)
+// strapiGenres est la collection Strapi des genres (films et séries).
+// Un genre est unique par id_genre ; is_movie et is_tv indiquent à quelles listes TMDB il appartient,
+// tmdb_orphaned qu'il n'apparaît plus dans aucune.
+const strapiGenres = "genre-tv-shows"
+
+// genreCache associe id_genre → documentId Strapi, pour résoudre les relations genre_tv_films.
+// missing retient les id_genre absents de Strapi au dernier chargement, pour ne pas relire
+// la collection à chaque titre qui en porte un ; les deux sont vidés par resetGenreCache.
+var genreCache = struct {
+	sync.Mutex
+	ids     map[int]string
+	missing map[int]bool
+}{}
+
+// TMDBGenre représente un genre renvoyé par TMDB
+type TMDBGenre = tmdb.Genre
 
-// TMDBGenre représente l’enveloppe de réponse renvoyée par TMDB pour un genre
-type TMDBGenre struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+// genreSummary résume une synchronisation des genres. Orphaned ne compte que les genres
+// devenus orphelins lors de ce passage ; ceux déjà marqués comptent dans Unchanged.
+type genreSummary struct {
+	Created   int
+	Updated   int
+	Orphaned  int
+	Unchanged int
+	Failed    int
 }
 
-// GenreResponse représente l’enveloppe de réponse renvoyée par TMDB
-type GenreResponse struct {
-	Genres []TMDBGenre `json:"genres"`
+func (s genreSummary) String() string {
+	return fmt.Sprintf("%d créés, %d mis à jour, %d nouveaux orphelins, %d inchangés, %d en échec", s.Created, s.Updated, s.Orphaned, s.Unchanged, s.Failed)
 }
 
-func init() {
+// SyncGenres réconcilie la collection des genres avec les listes TMDB des films et des séries :
+// création des genres manquants, mise à jour des genres renommés (dans chaque locale) ou qui
+// changent de liste, et marquage tmdb_orphaned des genres que TMDB ne renvoie plus.
+func SyncGenres() {
+	ctx := context.Background()
+	log.Println("🔄 SyncGenres commencé")
 
-	if err := godotenv.Load(); err != nil {
-		log.Printf("⚠️ .env non chargé: %v", err)
+	// Genres TMDB par id_genre, et leur appartenance aux listes films / séries
+	tmdbGenres := map[int]*tmdbGenreEntry{}
+	for _, mediaType := range []string{tmdb.MediaMovie, tmdb.MediaTV} {
+		if err := collectGenres(ctx, mediaType, tmdbGenres); err != nil {
+			// Sans les deux listes complètes on ne peut pas détecter les orphelins
+			log.Printf("❌ TMDB genre/%s/list: %v", mediaType, err)
+			return
+		}
 	}
-	tmdbMovieGenreURL = "https://api.themoviedb.org/3/genre/movie/list"
-	tmdbTvGenreURL = "https://api.themoviedb.org/3/genre/tv/list"
-    strapiTvURL = os.Getenv("STRAPI_URL") + "/api/genre-tv-shows"
+	log.Printf("TMDB returned %d genres", len(tmdbGenres))
 
-
-	c := cron.New()
-	_, err := c.AddFunc("0 0 * * 0", func() {
-		log.Println("🚀 Exécution de SyncMovieGenres et SyncTvGenres chaque dimanche")
-		SyncMovieGenres()
-		SyncTvGenres()
-	})
+	stored, err := documents(strapiGenres).All(ctx, nil)
 	if err != nil {
-		log.Fatalf("Erreur planification cron: %v", err)
+		log.Printf("❌ Lecture des genres Strapi: %v", err)
+		return
+	}
+	storedByID := map[int]strapi.Document{}
+	for _, doc := range stored {
+		if id, err := strconv.Atoi(toString(doc["id_genre"])); err == nil {
+			storedByID[id] = doc
+		}
 	}
-	c.Start()
-}
-
 
+	var summary genreSummary
+	for id, g := range tmdbGenres {
+		payload := g.payload()
+		localized := g.localizations()
 
-func SyncMovieGenres() {
+		doc, exists := storedByID[id]
+		var res upsertResult
+		if !exists {
+			created, err := documents(strapiGenres).Create(ctx, payload)
+			if err != nil {
+				summary.Failed++
+				log.Printf("⚠️ Strapi a refusé le genre %s : %v", g.name, err)
+				continue
+			}
+			doc, res = derefDocument(created), upsertInserted
+		} else {
+			if oldName := toString(doc["nom_genre"]); oldName != g.name {
+				log.Printf("✏️ Genre %d renommé : %q → %q", id, oldName, g.name)
+			}
+			if res, doc, err = updateDocument(ctx, strapiGenres, doc, payload, "id_genre"); err != nil {
+				summary.Failed++
+				log.Printf("⚠️ Mise à jour du genre %s : %v", g.name, err)
+				continue
+			}
+		}
 
-	log.Println("🔄 SyncMovieGenres start")
-	syncGenres(tmdbMovieGenreURL, strapiTvURL)
-	log.Println("✅ SyncMovieGenres done")
-}
+		if err := writeLocalizations(ctx, strapiGenres, doc.DocumentID(), localized); err != nil {
+			summary.Failed++
+			log.Printf("⚠️ Traductions du genre %s : %v", g.name, err)
+			continue
+		}
+		switch res {
+		case upsertInserted:
+			summary.Created++
+			log.Printf("✅ inserted genre: %s (%d)", g.name, id)
+		case upsertUpdated:
+			summary.Updated++
+		default:
+			summary.Unchanged++
+		}
+	}
 
+	// Genres stockés que TMDB ne renvoie plus : on les marque sans les supprimer,
+	// des films et séries peuvent encore y être liés
+	for id, doc := range storedByID {
+		if _, ok := tmdbGenres[id]; ok {
+			continue
+		}
+		res, _, err := updateDocument(ctx, strapiGenres, doc, map[string]interface{}{
+			"tmdb_orphaned": true,
+			"is_movie":      false,
+			"is_tv":         false,
+		})
+		if err != nil {
+			summary.Failed++
+			log.Printf("⚠️ Marquage du genre orphelin %d : %v", id, err)
+			continue
+		}
+		if res != upsertUpdated {
+			summary.Unchanged++
+			continue
+		}
+		summary.Orphaned++
+		log.Printf("🪦 Genre %d (%s) n'est plus renvoyé par TMDB", id, toString(doc["nom_genre"]))
+	}
 
-func SyncTvGenres() {
-	log.Println("🔄 SyncTvGenres commencé ")
-	syncGenres(tmdbTvGenreURL, strapiTvURL)
-	log.Println("✅ SyncTvGenres terminé")
+	// Les nouveaux genres seront relus à la prochaine résolution de relation
+	resetGenreCache()
+	log.Printf("📊 Genres : %s", summary)
+	log.Println("✅ SyncGenres terminé")
+}
 
+// tmdbGenreEntry est un genre TMDB avec ses noms par locale et ses listes d'appartenance
+type tmdbGenreEntry struct {
+	id      int
+	name    string
+	names   map[string]string
+	isMovie bool
+	isTv    bool
 }
 
-func syncGenres(tmdbURL, strapiURL string) {
-	strapiToken := os.Getenv("STRAPI_TOKEN")
+// collectGenres ajoute à genres la liste TMDB de mediaType, dans chaque locale
+func collectGenres(ctx context.Context, mediaType string, genres map[int]*tmdbGenreEntry) error {
+	for i, locale := range locales() {
+		list, err := tmdbClient.GenreList(ctx, mediaType, locale)
+		if err != nil {
+			return err
+		}
+		for _, g := range list {
+			entry, ok := genres[g.ID]
+			if !ok {
+				if i > 0 {
+					// Genre absent de la locale principale : ignoré
+					continue
+				}
+				entry = &tmdbGenreEntry{id: g.ID, names: map[string]string{}}
+				genres[g.ID] = entry
+			}
+			if i == 0 {
+				entry.name = g.Name
+				if mediaType == tmdb.MediaTV {
+					entry.isTv = true
+				} else {
+					entry.isMovie = true
+				}
+			} else if g.Name != "" {
+				entry.names[locale] = g.Name
+			}
+		}
+	}
+	return nil
+}
 
-	resp, err := http.Get(fmt.Sprintf("%s?api_key=%s&language=fr-FR", tmdbURL, os.Getenv("API_KEY")))
-	if err != nil {
-		log.Printf("❌ TMDB GET error: %v", err)
-		return
+// payload construit le document Strapi du genre (locale principale, et champs suffixés en mode "fields")
+func (g *tmdbGenreEntry) payload() map[string]interface{} {
+	payload := map[string]interface{}{
+		"id_genre":      g.id,
+		"nom_genre":     g.name,
+		"is_movie":      g.isMovie,
+		"is_tv":         g.isTv,
+		"tmdb_orphaned": false,
+	}
+	if syncConfig.LocaleMode == config.LocaleModeFields {
+		for locale, name := range g.localNames() {
+			payload["nom_genre_"+localeSuffix(locale)] = name
+		}
 	}
-	defer resp.Body.Close()
+	return payload
+}
 
-	var tmdbRes GenreResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tmdbRes); err != nil {
-		log.Printf("❌ JSON decode error: %v", err)
-		return
+// localizations renvoie les champs localisés du genre (mode "i18n")
+func (g *tmdbGenreEntry) localizations() map[string]map[string]interface{} {
+	out := map[string]map[string]interface{}{}
+	for locale, name := range g.localNames() {
+		out[locale] = map[string]interface{}{"nom_genre": name}
 	}
-	log.Printf("TMDB returned %d genres", len(tmdbRes.Genres))
-    for _, g := range tmdbRes.Genres {
-	
-
-		payload := map[string]interface{}{"data": map[string]interface{}{"id_genre": g.ID, "nom_genre": g.Name}}
-		body, _ := json.Marshal(payload)
-       
-		req, _ := http.NewRequest("POST", strapiURL, bytes.NewBuffer(body))
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+strapiToken)
-
-			// Faire la requête
-			res, err := http.DefaultClient.Do(req)
-			if err != nil {
-			log.Printf("❌ erreur de POST  Strapi pour %s: %v", g.Name, err)
-			continue
-			}
-			defer res.Body.Close()
+	return out
+}
 
-			// Lire tout le corps
-			bodyBytes, err := io.ReadAll(res.Body)
-			if err != nil {
-				log.Printf("❌ Lecture réponse pour %s: %v", g.Name, err)
-				continue
-			}
+// localNames renvoie le nom du genre dans chaque locale secondaire ;
+// un nom traduit vide retombe sur le nom de la locale principale
+func (g *tmdbGenreEntry) localNames() map[string]string {
+	out := map[string]string{}
+	for _, locale := range secondaryLocales() {
+		name := g.names[locale]
+		if name == "" {
+			name = g.name
+		}
+		out[locale] = name
+	}
+	return out
+}
 
-			if res.StatusCode >= 400 {
-				// Tenter un décodage générique
-				var data map[string]interface{}
-				if err := json.Unmarshal(bodyBytes, &data); err == nil {
-					// Chercher "error" puis "message"
-					if errObj, ok := data["error"].(map[string]interface{}); ok {
-						if msg, ok := errObj["message"].(string); ok {
-						log.Printf("⚠️ Strapi a renvoyé le code %d pour %s : %s", res.StatusCode, g.Name, msg)
-							continue
-						}
-					}
-				}
+// resolveGenres convertit des id_genre TMDB en documentId Strapi pour la relation genre_tv_films.
+// Les genres absents de Strapi (pas encore synchronisés) sont ignorés jusqu'au prochain
+// SyncGenres : la collection n'est relue que pour un id_genre encore jamais rencontré.
+func resolveGenres(ctx context.Context, ids []int) (relationSet, error) {
+	genreCache.Lock()
+	defer genreCache.Unlock()
 
-				log.Printf("⚠️ Strapi a renvoyé le code %d pour %s : %s", res.StatusCode, g.Name, string(bodyBytes))
-			} else {
-				log.Printf("✅ inserted genre: %s (%d)", g.Name, g.ID)
+	unknown := false
+	for _, id := range ids {
+		if _, ok := genreCache.ids[id]; !ok && !genreCache.missing[id] {
+			unknown = true
+			break
+		}
+	}
+	if unknown || genreCache.ids == nil {
+		docs, err := collection[strapi.Document](strapiGenres).All(ctx, strapi.NewQuery().Fields("id_genre"))
+		if err != nil {
+			return nil, fmt.Errorf("lecture des genres: %w", err)
+		}
+		genreCache.ids = make(map[int]string, len(docs))
+		for _, doc := range docs {
+			if id, err := strconv.Atoi(toString(doc["id_genre"])); err == nil {
+				genreCache.ids[id] = doc.DocumentID()
 			}
-
-
+		}
 	}
 
+	set := relationSet{}
+	for _, id := range ids {
+		if documentID, ok := genreCache.ids[id]; ok {
+			set = append(set, documentID)
+		} else if !genreCache.missing[id] {
+			// Signalé une fois, les titres suivants qui le portent ne relisent plus les genres
+			if genreCache.missing == nil {
+				genreCache.missing = map[int]bool{}
+			}
+			genreCache.missing[id] = true
+			log.Printf("⚠️ Genre %d absent de Strapi, relation ignorée (lancer /Genre)", id)
+		}
+	}
+	return set, nil
 }
 
+func resetGenreCache() {
+	genreCache.Lock()
+	genreCache.ids = nil
+	genreCache.missing = nil
+	genreCache.Unlock()
+}
 
 func GenreTVShowHandler(w http.ResponseWriter, r *http.Request) {
-	go SyncMovieGenres()
-	go SyncTvGenres()
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "Synchronisation des genres déclenchée")
+	triggerJob(w, "genres", "Synchronisation des genres déclenchée")
 }
-
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"mon-projet/internal/strapi"
+)
+
+func TestResolveGenresCachesMissingGenres(t *testing.T) {
+	reads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reads++
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]interface{}{
+			{"documentId": "drame", "id_genre": 18},
+			{"documentId": "comedie", "id_genre": 35},
+		}})
+	}))
+	defer srv.Close()
+	prevClient := strapiClient
+	defer func() { strapiClient = prevClient }()
+	strapiClient = strapi.New(srv.URL, "")
+	resetGenreCache()
+	defer resetGenreCache()
+
+	steps := []struct {
+		name      string
+		ids       []int
+		reset     bool
+		want      relationSet
+		wantReads int
+	}{
+		{"premier chargement", []int{18, 99}, false, relationSet{"drame"}, 1},
+		{"genre absent déjà connu : pas de relecture", []int{99, 35}, false, relationSet{"comedie"}, 1},
+		{"nouveau genre absent : relecture", []int{18, 98}, false, relationSet{"drame"}, 2},
+		{"genres absents connus", []int{98, 99}, false, relationSet{}, 2},
+		{"après SyncGenres : relecture", []int{99}, true, relationSet{}, 3},
+	}
+	for _, step := range steps {
+		if step.reset {
+			resetGenreCache()
+		}
+		got, err := resolveGenres(context.Background(), step.ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, step.want) {
+			t.Errorf("%s : resolveGenres(%v) = %v, attendu %v", step.name, step.ids, got, step.want)
+		}
+		if reads != step.wantReads {
+			t.Errorf("%s : %d lectures des genres, attendu %d", step.name, reads, step.wantReads)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"mon-projet/internal/scheduler"
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// ImportOptions précise ce qui est synchronisé en plus du titre lors d'un import à la demande
+type ImportOptions struct {
+	// Genres relance la synchronisation des genres avant l'import (voir runGenresBeforeImport)
+	Genres bool
+	// Credits enregistre la distribution et l'équipe technique (voir SyncCredits)
+	Credits bool
+	// Recommendations enregistre les recommandations TMDB du titre
+	Recommendations bool
+}
+
+// ImportFilm enregistre tout de suite le film tmdbID, sans attendre que discover l'atteigne :
+// détail, traductions et mots-clés passent par upsertFilm, comme pour SyncMovies, puis enrichFilm.
+// Renvoie le document Strapi final (genres et mots-clés peuplés) et created s'il vient d'être inséré.
+func ImportFilm(ctx context.Context, tmdbID int, opts ImportOptions) (doc strapi.Document, created bool, err error) {
+	if opts.Genres {
+		if err := runGenresBeforeImport(); err != nil {
+			return nil, false, err
+		}
+	}
+	details, err := tmdbClient.MovieDetails(ctx, tmdbID, movieDetailsAppend...)
+	if err != nil {
+		return nil, false, fmt.Errorf("détail TMDB du film %d: %w", tmdbID, err)
+	}
+
+	rel := titleRelations{Translations: details.Translations}
+	if rel.Keywords, err = resolveKeywords(ctx, detailsKeywords(details.Keywords)); err != nil {
+		return nil, false, err
+	}
+	res, doc, err := upsertFilm(ctx, details.AsMovie(), 0, rel)
+	if err != nil {
+		return nil, false, fmt.Errorf("film %d: %w", tmdbID, err)
+	}
+	created = res == upsertInserted
+	if err := enrichFilm(ctx, doc, details); err != nil {
+		return nil, created, fmt.Errorf("détail Strapi du film %d: %w", tmdbID, err)
+	}
+	if err := importExtras(ctx, storedTitle{mediaType: tmdb.MediaMovie, tmdbID: tmdbID, doc: doc}, opts); err != nil {
+		return nil, created, err
+	}
+
+	doc, err = findByTMDBID(ctx, strapiFilms, "id_film", tmdbID, "genre_tv_films", "keywords")
+	return doc, created, err
+}
+
+// ImportTvShow enregistre tout de suite la série tmdbID avec ses saisons et épisodes
+// (voir ImportFilm)
+func ImportTvShow(ctx context.Context, tmdbID int, opts ImportOptions) (doc strapi.Document, created bool, err error) {
+	if opts.Genres {
+		if err := runGenresBeforeImport(); err != nil {
+			return nil, false, err
+		}
+	}
+	details, err := tmdbClient.TVDetails(ctx, tmdbID, tvDetailsAppend...)
+	if err != nil {
+		return nil, false, fmt.Errorf("détail TMDB de la série %d: %w", tmdbID, err)
+	}
+
+	rel := titleRelations{Translations: details.Translations}
+	if rel.Keywords, err = resolveKeywords(ctx, detailsKeywords(details.Keywords)); err != nil {
+		return nil, false, err
+	}
+	res, doc, err := upsertTvShow(ctx, details.AsTVShow(), 0, rel)
+	if err != nil {
+		return nil, false, fmt.Errorf("série %d: %w", tmdbID, err)
+	}
+	created = res == upsertInserted
+	if err := enrichTvShow(ctx, doc, details); err != nil {
+		return nil, created, fmt.Errorf("détail Strapi de la série %d: %w", tmdbID, err)
+	}
+	if err := importExtras(ctx, storedTitle{mediaType: tmdb.MediaTV, tmdbID: tmdbID, doc: doc}, opts); err != nil {
+		return nil, created, err
+	}
+
+	doc, err = findByTMDBID(ctx, strapiTvShows, "id_TvShow", tmdbID, "genre_tv_films", "keywords")
+	return doc, created, err
+}
+
+// runGenresBeforeImport exécute le job genres et attend sa fin, en passant par le scheduler
+// pour ne pas tourner en même temps qu'une synchronisation planifiée, ici ou sur une autre
+// instance. Si le job est déjà en cours, l'import continue avec les genres déjà stockés.
+func runGenresBeforeImport() error {
+	if jobScheduler == nil {
+		return fmt.Errorf("synchronisation des genres: jobs non enregistrés (voir RegisterJobs)")
+	}
+	_, err := jobScheduler.Run("genres", scheduler.TriggerImport)
+	var running *scheduler.AlreadyRunningError
+	if errors.As(err, &running) {
+		log.Printf("ℹ️ Genres déjà en cours de synchronisation, import avec les genres stockés: %v", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("synchronisation des genres: %w", err)
+	}
+	return nil
+}
+
+// importExtras synchronise les crédits et recommandations demandés dans opts.
+// Les recommandations d'un titre importé sont rattachées à la page 0, hors du parcours par page.
+func importExtras(ctx context.Context, t storedTitle, opts ImportOptions) error {
+	if opts.Credits {
+		if err := syncTitleCredits(ctx, t); err != nil {
+			return fmt.Errorf("crédits %s %d: %w", t.mediaType, t.tmdbID, err)
+		}
+	}
+	if opts.Recommendations {
+		var err error
+		if t.mediaType == tmdb.MediaTV {
+			err = syncTvShowRecommendations(ctx, t.tmdbID, 0)
+		} else {
+			err = syncFilmRecommendations(ctx, t.tmdbID, 0)
+		}
+		if err != nil {
+			return fmt.Errorf("recommandations %s %d: %w", t.mediaType, t.tmdbID, err)
+		}
+	}
+	return nil
+}
+
+// ImportFilmHandler traite POST /Films/{tmdbId}?genres=true&credits=true&recommendations=true
+func ImportFilmHandler(w http.ResponseWriter, r *http.Request) {
+	importHandler(w, r, ImportFilm)
+}
+
+// ImportTvShowHandler traite POST /TvShows/{tmdbId} (mêmes options que ImportFilmHandler)
+func ImportTvShowHandler(w http.ResponseWriter, r *http.Request) {
+	importHandler(w, r, ImportTvShow)
+}
+
+// importHandler lit {tmdbId} et les options, importe le titre et renvoie le document Strapi
+// en JSON : 201 s'il a été créé, 200 s'il existait déjà, 404 s'il est inconnu de TMDB
+func importHandler(w http.ResponseWriter, r *http.Request, importTitle func(context.Context, int, ImportOptions) (strapi.Document, bool, error)) {
+	tmdbID, err := strconv.Atoi(r.PathValue("tmdbId"))
+	if err != nil || tmdbID <= 0 {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("identifiant TMDB invalide: %q", r.PathValue("tmdbId")))
+		return
+	}
+	opts, err := importOptionsFromQuery(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	doc, created, err := importTitle(r.Context(), tmdbID, opts)
+	if err != nil {
+		log.Printf("❌ Import %s: %v", r.URL.Path, err)
+		status := http.StatusBadGateway
+		if tmdb.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	log.Printf("✅ Import %s terminé", r.URL.Path)
+	writeJSON(w, status, doc)
+}
+
+// importOptionsFromQuery lit les booléens genres, credits et recommendations de la query string
+func importOptionsFromQuery(r *http.Request) (ImportOptions, error) {
+	var opts ImportOptions
+	for name, target := range map[string]*bool{
+		"genres":          &opts.Genres,
+		"credits":         &opts.Credits,
+		"recommendations": &opts.Recommendations,
+	} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("paramètre %s invalide: %q", name, value)
+		}
+		*target = b
+	}
+	return opts, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("⚠️ Écriture de la réponse JSON: %v", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
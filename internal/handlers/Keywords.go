@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// strapiKeywords est la collection Strapi des mots-clés, liée aux films et séries par la relation keywords
+const strapiKeywords = "keywords"
+
+// SyncKeywords parcourt les titres stockés (checkpoints keywords:movie et keywords:tv)
+// et relie chacun à ses mots-clés TMDB
+func SyncKeywords() {
+	ctx := context.Background()
+	syncStoredTitles(ctx, "keywords:movie", tmdb.MediaMovie, syncConfig.Enrichment.PagesPerRun, syncTitleKeywords)
+	syncStoredTitles(ctx, "keywords:tv", tmdb.MediaTV, syncConfig.Enrichment.PagesPerRun, syncTitleKeywords)
+}
+
+func syncTitleKeywords(ctx context.Context, t storedTitle) error {
+	keywords, err := tmdbClient.Keywords(ctx, t.mediaType, t.tmdbID)
+	if err != nil {
+		return fmt.Errorf("mots-clés TMDB: %w", err)
+	}
+	set, err := resolveKeywords(ctx, keywords)
+	if err != nil {
+		return err
+	}
+
+	// La relation doit être peuplée pour être comparée (voir sameRelation)
+	name, field := mediaCollection(t.mediaType)
+	stored, err := findByTMDBID(ctx, name, field, t.tmdbID, "keywords")
+	if err != nil || stored == nil {
+		return err
+	}
+	return setTitleKeywords(ctx, t.mediaType, stored, set)
+}
+
+// setTitleKeywords remplace les mots-clés du titre doc s'ils ont changé
+func setTitleKeywords(ctx context.Context, mediaType string, doc strapi.Document, keywords relationSet) error {
+	name, _ := mediaCollection(mediaType)
+	if _, _, err := updateDocument(ctx, name, doc, map[string]interface{}{"keywords": keywords}); err != nil {
+		return fmt.Errorf("mots-clés Strapi: %w", err)
+	}
+	return nil
+}
+
+// resolveKeywords crée ou renomme (upsert par id_keyword) les mots-clés et renvoie
+// la relation correspondante. Renvoie nil si keywords est nil (mots-clés non demandés à TMDB).
+func resolveKeywords(ctx context.Context, keywords []tmdb.Keyword) (relationSet, error) {
+	if keywords == nil {
+		return nil, nil
+	}
+	ids := make([]int, 0, len(keywords))
+	for _, k := range keywords {
+		ids = append(ids, k.ID)
+	}
+	stored, err := findManyByTMDBID(ctx, strapiKeywords, "id_keyword", ids)
+	if err != nil {
+		return nil, fmt.Errorf("lecture des mots-clés: %w", err)
+	}
+
+	set := relationSet{}
+	for _, k := range keywords {
+		payload := map[string]interface{}{"id_keyword": k.ID, "name": k.Name}
+		if doc, ok := stored[k.ID]; ok {
+			if _, _, err := updateDocument(ctx, strapiKeywords, doc, payload, "id_keyword"); err != nil {
+				return nil, fmt.Errorf("mot-clé %d: %w", k.ID, err)
+			}
+			set = append(set, doc.DocumentID())
+			continue
+		}
+		created, err := collection[strapi.Document](strapiKeywords).Create(ctx, payload)
+		if err != nil {
+			return nil, fmt.Errorf("mot-clé %d: %w", k.ID, err)
+		}
+		set = append(set, derefDocument(created).DocumentID())
+	}
+	return set, nil
+}
+
+// enrichKeywords relie un titre enrichi aux mots-clés reçus avec son détail
+func enrichKeywords(ctx context.Context, mediaType string, t enrichTarget, keywords *tmdb.KeywordList) error {
+	set, err := resolveKeywords(ctx, detailsKeywords(keywords))
+	if err != nil || set == nil {
+		return err
+	}
+	if err := setTitleKeywords(ctx, mediaType, t.doc, set); err != nil {
+		return fmt.Errorf("%s %d: %w", mediaType, t.tmdbID, err)
+	}
+	return nil
+}
+
+// detailsKeywords renvoie les mots-clés d'une réponse de détail (nil s'ils n'ont pas été demandés)
+func detailsKeywords(k *tmdb.KeywordList) []tmdb.Keyword {
+	if k == nil {
+		return nil
+	}
+	if all := k.All(); all != nil {
+		return all
+	}
+	return []tmdb.Keyword{}
+}
+
+func KeywordsHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "keywords", "Synchronisation des mots-clés déclenchée")
+}
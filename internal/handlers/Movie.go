@@ -1,168 +1,181 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
 	"log"
 	"net/http"
-	"os"
+	"net/url"
 
-	"github.com/joho/godotenv"
-	cron "github.com/robfig/cron/v3"
+	"mon-projet/internal/config"
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
 )
 
-var (
-	tmdbMovieURL  string
-	strapiFilmURL string
-)
+// strapiFilms est la collection Strapi des films
+const strapiFilms = "films"
 
 // TMDBMovie représente un film renvoyé par TMDB
-// GenreIDs est un slice d'entiers car TMDB renvoie [id1, id2, ...]
-type TMDBMovie struct {
-	ID               int     `json:"id"`
-	Adult            bool    `json:"adult"`
-	BackdropPath     string  `json:"backdrop_path"`
-	OriginalTitle    string  `json:"original_title"`
-	OriginalLanguage string  `json:"original_language"`
-	Overview         string  `json:"overview"`
-	PosterPath       string  `json:"poster_path"`
-	ReleaseDate      string  `json:"release_date"`
-	Title            string  `json:"title"`
-	Video            bool    `json:"video"`
-	VoteAverage      float64 `json:"vote_average"`
-	VoteCount        int     `json:"vote_count"`
-	Popularity       float64 `json:"popularity"`
-	GenreIDs         []int   `json:"genre_ids"`
-}
+type TMDBMovie = tmdb.Movie
 
 // MovieResponse enveloppe la réponse TMDB pour discover/movie
-type MovieResponse struct {
-	Page       int         `json:"page"`
-	Results    []TMDBMovie `json:"results"`
-	TotalPages int         `json:"total_pages"`
-}
-
-func init() {
-
-	tmdbMovieURL = "https://api.themoviedb.org/3/discover/movie"
-	strapiFilmURL = os.Getenv("STRAPI_URL") + "/api/films"
-
-	if err := godotenv.Load(); err != nil {
-		log.Printf("⚠️ .env non chargé: %v", err)
-	}
-
-	c := cron.New()
-	_, err := c.AddFunc("0 * * * *", func() {
-		log.Println("🚀 Lancement planifié: SyncMovies chaque une heure ")
-		SyncMovies()
-	})
-	if err != nil {
-		log.Fatalf("Erreur cron SyncMovies: %v", err)
-	}
-	c.Start()
-
-}
+type MovieResponse = tmdb.MoviePage
 
 // la fonction SyncMovies est la responsable de recuperer les données
-// vérifier si les données existe pas dans la base de données
+// vérifier si les données existe pas dans la base de données (sinon mettre à jour les champs TMDB modifiés)
 // recueprer pour chaque film les genres qui le correspond
 // et enfin les stocker dans la table films
 func SyncMovies() {
-	lastPage := getLastFetchedPage(strapiFilmURL)
-	nextPage := lastPage + 1
-    log.Printf("🔄 Sync Movies : récupération de la page %d depuis TMDB", nextPage)
+	ctx := context.Background()
+	for _, p := range syncConfig.Movies.Pipelines {
+		syncMoviesPipeline(ctx, p)
+	}
+}
 
-	tmdbURL := fmt.Sprintf("%s?api_key=%s&language=fr-FR&page=%d", tmdbMovieURL, os.Getenv("API_KEY"), nextPage)
-	resp, err := http.Get(tmdbURL)
+// syncMoviesPipeline avance d'une page le pipeline discover p, avec son propre checkpoint
+func syncMoviesPipeline(ctx context.Context, p config.Pipeline) {
+	job := pipelineJob("movies", p)
+	var legacy func() int
+	if p.Name == config.DefaultPipeline {
+		legacy = func() int { return getLastFetchedPage(ctx, strapiFilms, "page_fetched_from") }
+	}
+	cp, err := loadCheckpoint(ctx, job, legacy)
 	if err != nil {
-		log.Printf("❌ Erreur TMDB GET: %v", err)
+		log.Printf("❌ Lecture du checkpoint %s: %v", job, err)
 		return
 	}
-	defer resp.Body.Close()
+	params := p.Discover.Params(tmdb.MediaMovie)
 
-	var mr MovieResponse
-	if err := json.NewDecoder(resp.Body).Decode(&mr); err != nil {
-		log.Printf("❌ JSON decode TMDB: %v", err)
-		return
+	// On retente d'abord une page en échec, puis la page suivante
+	for _, page := range cp.Pending() {
+		recordPage(&cp, page, syncMoviesPage(ctx, params, page))
+	}
+	saveCheckpoint(ctx, cp)
+}
+
+// syncMoviesPage synchronise une page discover/movie.
+// Une page au-delà de la dernière page TMDB est pageEmpty, une page illisible pageUnread ;
+// une page n'est pageDone que si tous ses titres ont été insérés ou mis à jour.
+func syncMoviesPage(ctx context.Context, params url.Values, nextPage int) pageOutcome {
+	log.Printf("🔄 Sync Movies : récupération de la page %d depuis TMDB", nextPage)
+
+	mr, err := tmdbClient.DiscoverMovies(ctx, nextPage, params)
+	if err != nil {
+		log.Printf("❌ Erreur TMDB discover/movie: %v", err)
+		return pageUnread
 	}
 
 	/* J'ai ajouté cette condition pour vérifier si on a atteint la fin de l'API. */
 	if len(mr.Results) == 0 {
 		log.Printf("✅ Plus de films à synchroniser. Toutes les pages TMDB sont terminées.")
-		return
+		return pageEmpty
 	}
 
 	log.Printf("📦 TMDB page %d: %d films, total pages %d", mr.Page, len(mr.Results), mr.TotalPages)
 
 	allSuccess := true
-	endpoint := strapiFilmURL + "?filters[id_film][$eq]"
+	var stats SyncStats
+	var toEnrich []enrichTarget
 
 	for _, m := range mr.Results {
-		exists, err := Exists(m.ID, endpoint)
-		if err != nil {
-			log.Printf("⚠️ check exists error for %d: %v", m.ID, err)
-			continue
-		}
-		if exists {
-			log.Printf("ℹ️ Film existant, skip: %s (%d)", m.Title, m.ID)
-			continue
-		}
-
-		payload := map[string]interface{}{"data": map[string]interface{}{
-			"id_film":              m.ID,
-			"title":                m.Title,
-			"original_title":       m.OriginalTitle,
-			"original_language":    m.OriginalLanguage,
-			"overview":             m.Overview,
-			"Backdrop_path":        m.BackdropPath,
-			"poster_path":          m.PosterPath,
-			"release_date":         m.ReleaseDate,
-			"Video":                m.Video,
-			"vote_average_tmdb":    m.VoteAverage,
-			"vote_count_tmdb":      m.VoteCount,
-			"popularity_tmdb":      m.Popularity,
-			"genre_tv_films":       m.GenreIDs,
-			"adult":                m.Adult,
-			"popularity_website":   0.0,
-			"vote_average_website": 0.0,
-			"vote_count_website":   0.0,
-			"page_fetched_from":    nextPage,
-		}}
-
-		b, _ := json.Marshal(payload)
-		req, _ := http.NewRequest("POST", strapiFilmURL, bytes.NewBuffer(b))
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+strapiToken)
-
-		res, err := http.DefaultClient.Do(req)
+		res, doc, err := upsertFilm(ctx, m, nextPage, titleRelations{})
 		if err != nil {
-			log.Printf("❌ POST Strapi film %d: %v", m.ID, err)
+			log.Printf("❌ Upsert Strapi film %d: %v", m.ID, err)
 			allSuccess = false
+			stats.Failed++
 			continue
 		}
-		res.Body.Close()
-
-		if res.StatusCode >= 400 {
-			log.Printf("⚠️ Strapi returned %d for film %d", res.StatusCode, m.ID)
-			allSuccess = false
-		} else {
+		stats.add(res)
+		switch res {
+		case upsertInserted:
 			log.Printf("✅ Film inséré: %s (%d)", m.Title, m.ID)
+		case upsertUpdated:
+			log.Printf("🔄 Film mis à jour: %s (%d)", m.Title, m.ID)
+		}
+		if needsEnrichment(res, doc) {
+			toEnrich = append(toEnrich, enrichTarget{tmdbID: m.ID, doc: doc})
 		}
 	}
 
+	log.Printf("📊 Films page %d : %s", nextPage, stats)
+
+	// Détail complet (durée, budget, sociétés…) des films nouveaux ou modifiés
+	if failed := enrichMovies(ctx, toEnrich); failed > 0 {
+		log.Printf("⚠️ Détail de %d/%d films de la page %d non enregistré", failed, len(toEnrich), nextPage)
+		allSuccess = false
+	}
+
 	// Si tous les films ont été correctement insérés, on peut dire que la page est traitée
 	if !allSuccess {
 		log.Printf("⚠️ Tous les films de la page %d n'ont pas été insérés. On retentera plus tard.", nextPage)
 	} else {
 		log.Printf("✅ Tous les films de la page %d ont été insérés avec succès.", nextPage)
 	}
+	if !allSuccess {
+		return pageFailed
+	}
+	return pageDone
+}
+
+// upsertFilm enregistre le film m (insertion ou mise à jour des champs TMDB modifiés)
+// avec ses traductions et les relations connues de rel (les genres sont résolus ici).
+// C'est le chemin d'écriture commun à toutes les synchronisations de films.
+func upsertFilm(ctx context.Context, m TMDBMovie, page int, rel titleRelations) (upsertResult, strapi.Document, error) {
+	var err error
+	if rel.Genres, err = resolveGenres(ctx, m.GenreIDs); err != nil {
+		return 0, nil, err
+	}
+	payload := filmPayload(m, page, rel)
+	var translations map[string]titleTranslation
+	if rel.Translations != nil {
+		translations = titleTranslations(rel.Translations, m.OriginalTitle, m.Overview)
+		addLocaleFields(payload, "title", translations)
+	}
 
+	res, doc, err := upsertByTMDBID(ctx, strapiFilms, "id_film", m.ID, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	doc, err = upsertTitleTranslations(ctx, strapiFilms, "title", res, doc, translations, func() (map[string]titleTranslation, error) {
+		return fetchTitleTranslations(ctx, tmdb.MediaMovie, m.ID, m.OriginalTitle, m.Overview)
+	})
+	return res, doc, err
 }
 
+// filmPayload construit le document Strapi d'un film TMDB (locale principale).
+// Un titre vide dans la locale principale retombe sur original_title.
+// genre_tv_films est une relation vers la collection des genres (voir resolveGenres),
+// keywords n'est envoyé que si les mots-clés sont connus (voir titleRelations).
+// Les champs *_website et page_fetched_from ne sont pris en compte qu'à l'insertion (voir upsertByTMDBID)
+func filmPayload(m TMDBMovie, page int, rel titleRelations) map[string]interface{} {
+	title := m.Title
+	if title == "" {
+		title = m.OriginalTitle
+	}
+
+	payload := map[string]interface{}{
+		"id_film":              m.ID,
+		"title":                title,
+		"original_title":       m.OriginalTitle,
+		"original_language":    m.OriginalLanguage,
+		"overview":             m.Overview,
+		"Backdrop_path":        m.BackdropPath,
+		"poster_path":          m.PosterPath,
+		"release_date":         m.ReleaseDate,
+		"Video":                m.Video,
+		"vote_average_tmdb":    m.VoteAverage,
+		"vote_count_tmdb":      m.VoteCount,
+		"popularity_tmdb":      m.Popularity,
+		"genre_tv_films":       rel.Genres,
+		"adult":                m.Adult,
+		"popularity_website":   0.0,
+		"vote_average_website": 0.0,
+		"vote_count_website":   0.0,
+		"page_fetched_from":    page,
+	}
+	rel.add(payload)
+	return payload
+}
 
 func MovieHandler(w http.ResponseWriter, r *http.Request) {
-	go SyncMovies()
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "Synchronisation des films déclenchée")
+	triggerJob(w, "movies", "Synchronisation des films déclenchée")
 }
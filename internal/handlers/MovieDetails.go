@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"mon-projet/internal/config"
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// movieDetailsAppend liste les sous-ressources demandées avec le détail d'un film
+// (append_to_response) : les traductions donnent le tagline de chaque locale et les mots-clés
+// la relation keywords, sans appel supplémentaire
+var movieDetailsAppend = []string{"translations", "keywords"}
+
+// enrichTarget est un titre stocké dont il faut compléter le détail
+type enrichTarget struct {
+	tmdbID int
+	doc    strapi.Document
+}
+
+// needsEnrichment indique si un titre doit être enrichi après son upsert : s'il est nouveau
+// ou modifié, ou si un enrichissement précédent n'a pas abouti (details_synced_at vide)
+func needsEnrichment(res upsertResult, doc strapi.Document) bool {
+	return res != upsertUnchanged || isEmpty(doc["details_synced_at"])
+}
+
+// enrichMovies récupère /movie/{id} pour chaque film et enregistre ses champs de détail,
+// par lots (voir runBatched). Renvoie le nombre de films en échec.
+func enrichMovies(ctx context.Context, targets []enrichTarget) int {
+	return runBatched(ctx, targets, func(t enrichTarget) error {
+		details, err := tmdbClient.MovieDetails(ctx, t.tmdbID, movieDetailsAppend...)
+		if err != nil {
+			return fmt.Errorf("détail TMDB du film %d: %w", t.tmdbID, err)
+		}
+		if err := enrichFilm(ctx, t.doc, details); err != nil {
+			return fmt.Errorf("détail Strapi du film %d: %w", t.tmdbID, err)
+		}
+		return enrichKeywords(ctx, tmdb.MediaMovie, t, details.Keywords)
+	})
+}
+
+// enrichFilm écrit les champs de détail d'un film déjà stocké (doc) et synchronise sa saga
+func enrichFilm(ctx context.Context, doc strapi.Document, d *tmdb.MovieDetails) error {
+	payload := movieDetailsPayload(d)
+
+	localized := map[string]map[string]interface{}{}
+	for _, locale := range secondaryLocales() {
+		tagline := d.Tagline
+		if d.Translations != nil {
+			if tr := d.Translations.Find(locale); tr != nil && tr.Tagline != "" {
+				tagline = tr.Tagline
+			}
+		}
+		localized[locale] = map[string]interface{}{"tagline": tagline}
+		if syncConfig.LocaleMode == config.LocaleModeFields {
+			payload["tagline_"+localeSuffix(locale)] = tagline
+		}
+	}
+
+	if _, _, err := updateDocument(ctx, strapiFilms, doc, payload); err != nil {
+		return err
+	}
+	if err := writeLocalizations(ctx, strapiFilms, doc.DocumentID(), localized); err != nil {
+		return err
+	}
+	// La saga relie ses films stockés : elle est relue maintenant que ce film en fait partie
+	if d.BelongsToCollection != nil {
+		return syncFilmCollectionOnce(ctx, d.BelongsToCollection.ID)
+	}
+	return nil
+}
+
+// movieDetailsPayload construit les champs de détail d'un film (/movie/{id})
+func movieDetailsPayload(d *tmdb.MovieDetails) map[string]interface{} {
+	return map[string]interface{}{
+		"runtime":              d.Runtime,
+		"budget":               d.Budget,
+		"revenue":              d.Revenue,
+		"status":               d.Status,
+		"tagline":              d.Tagline,
+		"homepage":             d.Homepage,
+		"imdb_id":              d.IMDbID,
+		"spoken_languages":     d.SpokenLanguages,
+		"production_countries": d.ProductionCountries,
+		"production_companies": d.ProductionCompanies,
+		"details_synced_at":    time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// runBatched appelle fn pour chaque élément, par lots de Enrichment.BatchSize séparés
+// d'une pause Enrichment.BatchPause, pour rester sous la limite de débit TMDB.
+// Les erreurs sont journalisées ; renvoie le nombre d'éléments en échec.
+func runBatched[T any](ctx context.Context, items []T, fn func(T) error) int {
+	batchSize := syncConfig.Enrichment.BatchSize
+	pause := time.Duration(syncConfig.Enrichment.BatchPause)
+
+	failed := 0
+	for i, item := range items {
+		if i > 0 && i%batchSize == 0 {
+			select {
+			case <-ctx.Done():
+				log.Printf("⚠️ Enrichissement interrompu: %v", ctx.Err())
+				return failed + len(items) - i
+			case <-time.After(pause):
+			}
+		}
+		if err := fn(item); err != nil {
+			log.Printf("⚠️ %v", err)
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// Collections Strapi des personnes (acteurs, équipe technique) et de leurs crédits.
+// Un crédit relie une personne (person) à un film (film) ou à une série (tv_show).
+const (
+	strapiPeople  = "people"
+	strapiCredits = "credits"
+)
+
+// SyncCredits parcourt les films et séries stockés (dans le budget du passage, par type,
+// checkpoints credits:movie et credits:tv) et enregistre leur distribution et équipe technique
+// depuis /movie/{id}/credits et /tv/{id}/aggregate_credits.
+func SyncCredits() {
+	ctx := context.Background()
+	syncStoredTitles(ctx, "credits:movie", tmdb.MediaMovie, syncConfig.Enrichment.PagesPerRun, syncTitleCredits)
+	syncStoredTitles(ctx, "credits:tv", tmdb.MediaTV, syncConfig.Enrichment.PagesPerRun, syncTitleCredits)
+}
+
+// syncTitleCredits enregistre les personnes et les crédits d'un titre, et supprime
+// les crédits que TMDB ne renvoie plus
+func syncTitleCredits(ctx context.Context, t storedTitle) error {
+	var credits *tmdb.Credits
+	var err error
+	if t.mediaType == tmdb.MediaTV {
+		credits, err = tmdbClient.TVAggregateCredits(ctx, t.tmdbID)
+	} else {
+		credits, err = tmdbClient.MovieCredits(ctx, t.tmdbID)
+	}
+	if err != nil {
+		return fmt.Errorf("crédits TMDB: %w", err)
+	}
+
+	var people []tmdb.Person
+	for _, c := range credits.Cast {
+		people = append(people, c.Person)
+	}
+	for _, c := range credits.Crew {
+		people = append(people, c.Person)
+	}
+	personDocs, err := upsertPeople(ctx, people)
+	if err != nil {
+		return err
+	}
+
+	relation := titleRelationField(t.mediaType)
+	stored, err := collection[strapi.Document](strapiCredits).All(ctx, strapi.NewQuery().Related(relation, t.doc))
+	if err != nil {
+		return fmt.Errorf("lecture des crédits: %w", err)
+	}
+	byCreditID := make(map[string]strapi.Document, len(stored))
+	for _, doc := range stored {
+		byCreditID[toString(doc["credit_id"])] = doc
+	}
+
+	var stats SyncStats
+	write := func(creditID string, personID int, payload map[string]interface{}) error {
+		payload["credit_id"] = creditID
+		if doc, ok := byCreditID[creditID]; ok {
+			delete(byCreditID, creditID)
+			res, _, err := updateDocument(ctx, strapiCredits, doc, payload, "credit_id")
+			if err != nil {
+				return fmt.Errorf("crédit %s: %w", creditID, err)
+			}
+			stats.add(res)
+			return nil
+		}
+		// Les relations ne sont posées qu'à la création : un credit_id désigne toujours
+		// la même personne sur le même titre
+		payload["person"] = relationSet{personDocs[personID]}
+		payload[relation] = relationSet{t.doc.DocumentID()}
+		if _, err := collection[strapi.Document](strapiCredits).Create(ctx, payload); err != nil {
+			return fmt.Errorf("crédit %s: %w", creditID, err)
+		}
+		stats.add(upsertInserted)
+		return nil
+	}
+
+	for _, c := range credits.Cast {
+		if err := write(c.CreditID, c.ID, creditPayload("cast", c.Character, "", "Acting", c.Order, c.EpisodeCount)); err != nil {
+			return err
+		}
+	}
+	for _, c := range credits.Crew {
+		if err := write(c.CreditID, c.ID, creditPayload("crew", "", c.Job, c.Department, 0, c.EpisodeCount)); err != nil {
+			return err
+		}
+	}
+
+	for creditID, doc := range byCreditID {
+		if err := collection[strapi.Document](strapiCredits).Delete(ctx, doc.DocumentID()); err != nil {
+			return fmt.Errorf("suppression du crédit %s: %w", creditID, err)
+		}
+	}
+	log.Printf("🎭 Crédits %s %d : %s, %d supprimés", t.mediaType, t.tmdbID, stats, len(byCreditID))
+	return nil
+}
+
+// creditPayload construit les champs d'un crédit (kind vaut "cast" ou "crew")
+func creditPayload(kind, character, job, department string, order, episodeCount int) map[string]interface{} {
+	return map[string]interface{}{
+		"credit_type":   kind,
+		"character":     character,
+		"job":           job,
+		"department":    department,
+		"order":         order,
+		"episode_count": episodeCount,
+	}
+}
+
+// upsertPeople crée ou met à jour (upsert par id_person) les personnes et renvoie
+// le documentId Strapi de chacune, indexé par identifiant TMDB
+func upsertPeople(ctx context.Context, people []tmdb.Person) (map[int]string, error) {
+	unique := map[int]tmdb.Person{}
+	ids := make([]int, 0, len(people))
+	for _, p := range people {
+		if _, ok := unique[p.ID]; !ok {
+			unique[p.ID] = p
+			ids = append(ids, p.ID)
+		}
+	}
+	stored, err := findManyByTMDBID(ctx, strapiPeople, "id_person", ids)
+	if err != nil {
+		return nil, fmt.Errorf("lecture des personnes: %w", err)
+	}
+
+	docIDs := make(map[int]string, len(ids))
+	for _, id := range ids {
+		payload := personPayload(unique[id])
+		if doc, ok := stored[id]; ok {
+			if _, _, err := updateDocument(ctx, strapiPeople, doc, payload, "id_person"); err != nil {
+				return nil, fmt.Errorf("personne %d: %w", id, err)
+			}
+			docIDs[id] = doc.DocumentID()
+			continue
+		}
+		created, err := collection[strapi.Document](strapiPeople).Create(ctx, payload)
+		if strapi.IsUniqueViolation(err, "id_person") {
+			// Créée entre-temps (ex: import depuis la recherche) : on met à jour celle-ci
+			var doc strapi.Document
+			_, doc, err = upsertByTMDBID(ctx, strapiPeople, "id_person", id, payload)
+			created = &doc
+		}
+		if err != nil {
+			return nil, fmt.Errorf("personne %d: %w", id, err)
+		}
+		docIDs[id] = derefDocument(created).DocumentID()
+	}
+	return docIDs, nil
+}
+
+// personPayload construit les champs d'une personne présents dans les crédits TMDB
+func personPayload(p tmdb.Person) map[string]interface{} {
+	return map[string]interface{}{
+		"id_person":            p.ID,
+		"name":                 p.Name,
+		"profile_path":         p.ProfilePath,
+		"known_for_department": p.KnownForDepartment,
+	}
+}
+
+func PeopleHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "credits", "Synchronisation des crédits (acteurs et équipe technique) déclenchée")
+}
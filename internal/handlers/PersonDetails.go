@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// SyncPeopleDetails parcourt les personnes stockées (dans le budget du passage, checkpoint
+// people-details) et enregistre leur biographie et leur filmographie via
+// /person/{id}?append_to_response=combined_credits
+func SyncPeopleDetails() {
+	walkCollection(context.Background(), "people-details", strapiPeople, syncConfig.Enrichment.PagesPerRun, syncPersonDetails)
+}
+
+// syncPersonDetails enregistre le détail d'une personne déjà stockée. La filmographie
+// (filmography_films, filmography_tv_shows) ne relie que les titres présents dans Strapi.
+func syncPersonDetails(ctx context.Context, doc strapi.Document) error {
+	id, err := strconv.Atoi(toString(doc["id_person"]))
+	if err != nil {
+		return fmt.Errorf("id_person invalide sur le document %s", doc.DocumentID())
+	}
+	details, err := tmdbClient.PersonDetails(ctx, id, "combined_credits")
+	if err != nil {
+		return fmt.Errorf("détail TMDB de la personne %d: %w", id, err)
+	}
+
+	films, tvShows, err := filmography(ctx, details.CombinedCredits)
+	if err != nil {
+		return fmt.Errorf("filmographie de la personne %d: %w", id, err)
+	}
+
+	payload := personPayload(details.Person)
+	for key, value := range personDetailsPayload(details) {
+		payload[key] = value
+	}
+	payload["filmography_films"] = films
+	payload["filmography_tv_shows"] = tvShows
+
+	if _, _, err := upsertByTMDBID(ctx, strapiPeople, "id_person", id, payload); err != nil {
+		return fmt.Errorf("personne %d: %w", id, err)
+	}
+	return nil
+}
+
+// personDetailsPayload construit les champs de détail d'une personne (/person/{id})
+func personDetailsPayload(d *tmdb.PersonDetails) map[string]interface{} {
+	return map[string]interface{}{
+		"biography":         d.Biography,
+		"birthday":          d.Birthday,
+		"deathday":          d.Deathday,
+		"place_of_birth":    d.PlaceOfBirth,
+		"imdb_id":           d.IMDbID,
+		"homepage":          d.Homepage,
+		"also_known_as":     d.AlsoKnownAs,
+		"details_synced_at": time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// filmography renvoie les relations vers les films et séries stockés dans lesquels
+// la personne apparaît (rôle ou poste)
+func filmography(ctx context.Context, credits *tmdb.CombinedCredits) (films, tvShows relationSet, err error) {
+	ids := map[string][]int{}
+	seen := map[string]bool{}
+	if credits != nil {
+		for _, c := range append(credits.Cast, credits.Crew...) {
+			key := c.MediaType + ":" + strconv.Itoa(c.ID)
+			if !seen[key] {
+				seen[key] = true
+				ids[c.MediaType] = append(ids[c.MediaType], c.ID)
+			}
+		}
+	}
+
+	relations := map[string]relationSet{}
+	for _, mediaType := range []string{tmdb.MediaMovie, tmdb.MediaTV} {
+		name, field := mediaCollection(mediaType)
+		stored, err := findManyByTMDBID(ctx, name, field, ids[mediaType])
+		if err != nil {
+			return nil, nil, err
+		}
+		set := relationSet{}
+		for _, id := range ids[mediaType] {
+			if doc, ok := stored[id]; ok {
+				set = append(set, doc.DocumentID())
+			}
+		}
+		relations[mediaType] = set
+	}
+	return relations[tmdb.MediaMovie], relations[tmdb.MediaTV], nil
+}
+
+func PeopleDetailsHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "people-details", "Synchronisation du détail des personnes déclenchée")
+}
@@ -1,86 +1,87 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"github.com/joho/godotenv"
-	cron "github.com/robfig/cron/v3"
 )
 
+// strapiRecommendationFilms est la collection Strapi des recommandations de films
+const strapiRecommendationFilms = "recommendation-films"
 
-var (
-	tmdbRecommendationFilmURL  string
-	strapiRecommendationFilmURL string
-)
-
-
-func init () {
-  
-	tmdbRecommendationFilmURL = "https://api.themoviedb.org/3/movie/"
-	strapiRecommendationFilmURL = os.Getenv("STRAPI_URL") + "/api/recommendation-films"
-
-
-	if err := godotenv.Load(); err != nil {
-		log.Printf("⚠️ .env non chargé: %v", err)
-	}
+func SyncFilmsRecommendation() {
+	ctx := context.Background()
 
-    c := cron.New()
-	_, err := c.AddFunc("0 0 * * *", func() {
-		log.Println("🚀 Lancement planifié: SyncMovies chaque 24h")
-		SyncMovies()
+	// Ici on va recupèrer la page de films de strapi à traiter (checkpoint film-recommendations)
+	cp, err := loadCheckpoint(ctx, "film-recommendations", func() int {
+		return getLastFetchedPage(ctx, strapiRecommendationFilms, "page_fetched_from_strapi_film")
 	})
 	if err != nil {
-		log.Fatalf("Erreur cron SyncMovies: %v", err)
+		log.Printf("❌ Lecture du checkpoint film-recommendations: %v", err)
+		return
 	}
-	c.Start()
-
 
+	// Pages déjà parcourues par la synchronisation movies : une page sans titre y est normale
+	// (page_fetched_from n'est écrit qu'à l'insertion) et ne doit pas bloquer le curseur
+	discovered := discoveredPages(ctx, "movies")
+	for _, page := range cp.Pending() {
+		recordPage(&cp, page, syncFilmsRecommendationPage(ctx, page, discovered))
+	}
+	saveCheckpoint(ctx, cp)
 }
 
-func SyncFilmsRecommendation() {
+// syncFilmsRecommendationPage synchronise les recommandations des films stockés avec page_fetched_from = page.
+// Une page sans titre stocké est pageDone si elle est déjà parcourue (page <= discovered),
+// sinon pageUnread en attendant la synchronisation des films.
+func syncFilmsRecommendationPage(ctx context.Context, page, discovered int) pageOutcome {
+	log.Printf("🔄 Sync recommandations films : page Strapi %d", page)
 
-  // Ici on va recupèrer la page de film de strapi 
-  lastpage := getLastFetchedPageFilmStrapi(strapiRecommendationFilmURL)
-  nextPage := lastpage + 1
-  log.Printf("🔄 Sync Film Recommendation : fetching TMDB page %d", nextPage)
+	ids, err := getFilmsByPageStrapi(ctx, page)
+	if err != nil {
+		log.Printf("⚠️ Erreur lors de la récupération de la page %d: %v", page, err)
+		return pageUnread
+	}
+	if len(ids) == 0 {
+		if page <= discovered {
+			log.Printf("ℹ️ Aucun titre stocké pour la page %d, déjà parcourue par movies : page passée", page)
+			return pageDone
+		}
+		log.Printf("ℹ️ Aucun titre stocké pour la page %d, on attend la synchronisation", page)
+		return pageUnread
+	}
 
-  FilmsStrapiPage,err := getFilmsByPageStrapi(nextPage)
+	allSuccess := true
+	for _, tmdbID := range ids {
+		if err := syncFilmRecommendations(ctx, tmdbID, page); err != nil {
+			log.Printf("⚠️ Recommandations de %d: %v", tmdbID, err)
+			allSuccess = false
+		}
+	}
+	if !allSuccess {
+		return pageFailed
+	}
+	return pageDone
+}
 
-  if err != nil {	
-	log.Printf("⚠️ Erreur lors de la récupération de la page %d: %v", nextPage, err)
-	return
-  }
+func FilmRecommendationHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "film-recommendations", "Synchronisation des recommandations de film TV déclenchée")
+}
 
-  for _, tmdbID := range FilmsStrapiPage {
+// syncFilmRecommendations récupère toutes les pages de recommandations TMDB du film tmdbID
+// et les enregistre (upsert par id_film) avec page comme page_fetched_from_strapi_film
+func syncFilmRecommendations(ctx context.Context, tmdbID, page int) error {
 	log.Printf("🔄 Synchronisation des recommandations de films : récupération du film TMDB %d", tmdbID)
 
 	var recommendedIDs []int
-	page := 1
-
-	for {
-		url := fmt.Sprintf("%s%d/recommendations?api_key=%s&language=fr-FR&page=%d",
-			tmdbRecommendationFilmURL, tmdbID, os.Getenv("API_KEY"), page)
-
-		resp, err := http.Get(url)
+	for recPage := 1; ; recPage++ {
+		mr, err := tmdbClient.MovieRecommendations(ctx, tmdbID, recPage)
 		if err != nil {
-			log.Printf("⚠️ Erreur lors de la récupération des recommandations (page %d) pour le film %d: %v", page, tmdbID, err)
-			break
-		}
-		defer resp.Body.Close()
-
-		var mr MovieResponse
-		if err := json.NewDecoder(resp.Body).Decode(&mr); err != nil {
-			log.Printf("❌ JSON decode TMDB (page %d) pour film %d: %v", page, tmdbID, err)
-			break
+			return fmt.Errorf("recommandations TMDB (page %d): %w", recPage, err)
 		}
 
 		// Si aucun résultat sur cette page
 		if len(mr.Results) == 0 {
-			log.Printf("✅ Fin des recommandations pour le film %d (page %d vide)", tmdbID, page)
 			break
 		}
 
@@ -88,58 +89,25 @@ func SyncFilmsRecommendation() {
 			recommendedIDs = append(recommendedIDs, rec.ID)
 		}
 
-		if page >= mr.TotalPages {
+		if recPage >= mr.TotalPages {
 			break
 		}
-		page++
 	}
 
 	if len(recommendedIDs) == 0 {
 		log.Printf("ℹ️ Aucune recommandation trouvée pour le film %d", tmdbID)
-		continue
+		return nil
 	}
 
 	payload := map[string]interface{}{
-		"data": map[string]interface{}{
-			"id_film":           tmdbID,
-			"id_films_recommendations": recommendedIDs,
-			"page_fetched_from_strapi_film": nextPage,
-		},
-	}
-
-	body, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("❌ Erreur encodage JSON pour film %d: %v", tmdbID, err)
-		continue
-	}
-
-	req, err := http.NewRequest("POST", strapiRecommendationFilmURL, bytes.NewBuffer(body))
-	if err != nil {
-		log.Printf("❌ Erreur création requête POST Strapi: %v", err)
-		continue
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("STRAPI_TOKEN"))
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Printf("❌ Erreur envoi POST à Strapi: %v", err)
-		continue
+		"id_film":                       tmdbID,
+		"id_films_recommendations":      recommendedIDs,
+		"page_fetched_from_strapi_film": page,
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode >= 400 {
-		log.Printf("⚠️ Strapi a retourné %d pour film %d", res.StatusCode, tmdbID)
-	} else {
-		log.Printf("✅ Recommandations insérées pour film %d", tmdbID)
+	if _, _, err := upsertByTMDBID(ctx, strapiRecommendationFilms, "id_film", tmdbID, payload); err != nil {
+		return fmt.Errorf("strapi a refusé les recommandations: %w", err)
 	}
+	log.Printf("✅ Recommandations enregistrées pour film %d", tmdbID)
+	return nil
 }
-}
-
-func FilmRecommendationHandler(w http.ResponseWriter, r *http.Request) {
-	go SyncFilmsRecommendation()
-	w.WriteHeader(http.StatusOK)
-    fmt.Fprintf(w, "Synchronisation des recommandations de film TV déclenchée")
-}
-
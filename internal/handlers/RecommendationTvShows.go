@@ -1,143 +1,113 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"github.com/joho/godotenv"
-	cron "github.com/robfig/cron/v3"
 )
 
-var (
-	tmdbRecommendationTvShowsURL   string
-	strapiRecommendationTvShowsURL string
-)
-
-func init() {
+// strapiRecommendationTvShows est la collection Strapi des recommandations de séries TV
+const strapiRecommendationTvShows = "recommendation-tv-shows"
 
-	tmdbRecommendationTvShowsURL = "https://api.themoviedb.org/3/tv/"
-	strapiRecommendationTvShowsURL = os.Getenv("STRAPI_URL") + "/api/recommendation-tv-shows"
-
-	if err := godotenv.Load(); err != nil {
-		log.Printf("⚠️ .env non chargé: %v", err)
-	}
+func SyncTvShowsRecommendation() {
+	ctx := context.Background()
 
-	c := cron.New()
-	_, err := c.AddFunc("0 0 * * *", func() {
-		log.Println("🚀 Lancement planifié: SyncMovies chaque 24h")
-		SyncMovies()
+	// Ici on va recupèrer la page de séries TV de strapi à traiter (checkpoint tv-show-recommendations)
+	cp, err := loadCheckpoint(ctx, "tv-show-recommendations", func() int {
+		return getLastFetchedPage(ctx, strapiRecommendationTvShows, "page_fetched_from_strapi_TvShow")
 	})
 	if err != nil {
-		log.Fatalf("Erreur cron SyncMovies: %v", err)
+		log.Printf("❌ Lecture du checkpoint tv-show-recommendations: %v", err)
+		return
 	}
-	c.Start()
 
+	// Pages déjà parcourues par la synchronisation tv-shows : une page sans titre y est normale
+	// (page_fetched_from n'est écrit qu'à l'insertion) et ne doit pas bloquer le curseur
+	discovered := discoveredPages(ctx, "tv-shows")
+	for _, page := range cp.Pending() {
+		recordPage(&cp, page, syncTvShowsRecommendationPage(ctx, page, discovered))
+	}
+	saveCheckpoint(ctx, cp)
 }
 
-func SyncTvShowsRecommendation() {
-
-
-	// Ici on va recupèrer la page de film de strapi
-	lastpage := getLastFetchedPageTvShowsStrapi(strapiRecommendationTvShowsURL)
-	nextPage := lastpage + 1
-    log.Printf("🔄 Synchronisation des recommandations de séries TV : récupération de la page %d depuis TMDB", nextPage)
-
-	// Je suis arrivé ici , on doit ajouter une foncion getTvShowsPageStrapi
-	TvShowsStrapiPage, err := getTvShowsByPageStrapi(nextPage)
+// syncTvShowsRecommendationPage synchronise les recommandations des séries TV stockés avec page_fetched_from = page.
+// Une page sans titre stocké est pageDone si elle est déjà parcourue (page <= discovered),
+// sinon pageUnread en attendant la synchronisation des séries.
+func syncTvShowsRecommendationPage(ctx context.Context, page, discovered int) pageOutcome {
+	log.Printf("🔄 Sync recommandations séries TV : page Strapi %d", page)
 
+	ids, err := getTvShowsByPageStrapi(ctx, page)
 	if err != nil {
-		log.Printf("⚠️ Erreur lors de la récupération de la page %d: %v", nextPage, err)
-		return
+		log.Printf("⚠️ Erreur lors de la récupération de la page %d: %v", page, err)
+		return pageUnread
 	}
-
-	for _, tmdbID := range TvShowsStrapiPage {
-      log.Printf("🔄 Sync TV shows recommendation : récupération de la page %d depuis TMDB", nextPage)
-
-		var recommendedIDs []int
-		page := 1
-
-		for {
-			url := fmt.Sprintf("%s%d/recommendations?api_key=%s&language=fr-FR&page=%d",
-				tmdbRecommendationTvShowsURL, tmdbID, os.Getenv("API_KEY"), page)
-
-			resp, err := http.Get(url)
-			if err != nil {
-				log.Printf("⚠️ Erreur lors de la récupération des recommandations (page %d) pour le Tv Show %d: %v", page, tmdbID, err)
-				break
-			}
-			defer resp.Body.Close()
-
-			var mr TvShowResponse
-			if err := json.NewDecoder(resp.Body).Decode(&mr); err != nil {
-				log.Printf("❌ Erreur de décodage JSON depuis TMDB (page %d) pour la série TV %d : %v", page, tmdbID, err)
-				break
-			}
-
-			// Si aucun résultat sur cette page
-			if len(mr.Results) == 0 {
-				log.Printf("✅ Fin des recommandations pour le Tv-Shows %d (page %d vide)", tmdbID, page)
-				break
-			}
-
-			for _, rec := range mr.Results {
-				recommendedIDs = append(recommendedIDs, rec.ID)
-			}
-
-			if page >= mr.TotalPages {
-				break
-			}
-			page++
+	if len(ids) == 0 {
+		if page <= discovered {
+			log.Printf("ℹ️ Aucun titre stocké pour la page %d, déjà parcourue par tv-shows : page passée", page)
+			return pageDone
 		}
+		log.Printf("ℹ️ Aucun titre stocké pour la page %d, on attend la synchronisation", page)
+		return pageUnread
+	}
 
-		if len(recommendedIDs) == 0 {
-			log.Printf("ℹ️ Aucune recommandation trouvée pour le Tv Show %d", tmdbID)
-			continue
+	allSuccess := true
+	for _, tmdbID := range ids {
+		if err := syncTvShowRecommendations(ctx, tmdbID, page); err != nil {
+			log.Printf("⚠️ Recommandations de %d: %v", tmdbID, err)
+			allSuccess = false
 		}
+	}
+	if !allSuccess {
+		return pageFailed
+	}
+	return pageDone
+}
 
-		payload := map[string]interface{}{
-			"data": map[string]interface{}{
-				"id_TvShow":                       tmdbID,
-				"id_TvShow_recommendations":       recommendedIDs,
-				"page_fetched_from_strapi_TvShow": nextPage,
-			},
-		}
+func TvShowRecommendationHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "tv-show-recommendations", "Synchronisation des recommandations de séries TV déclenchée")
+}
 
-		body, err := json.Marshal(payload)
-		if err != nil {
-			log.Printf("❌ Erreur encodage JSON pour Tv Show %d: %v", tmdbID, err)
-			continue
-		}
+// syncTvShowRecommendations récupère toutes les pages de recommandations TMDB de la série tmdbID
+// et les enregistre (upsert par id_TvShow) avec page comme page_fetched_from_strapi_TvShow
+func syncTvShowRecommendations(ctx context.Context, tmdbID, page int) error {
+	log.Printf("🔄 Synchronisation des recommandations de séries TV : récupération de la série TMDB %d", tmdbID)
 
-		req, err := http.NewRequest("POST", strapiRecommendationTvShowsURL, bytes.NewBuffer(body))
+	var recommendedIDs []int
+	for recPage := 1; ; recPage++ {
+		mr, err := tmdbClient.TVRecommendations(ctx, tmdbID, recPage)
 		if err != nil {
-			log.Printf("❌ Erreur création requête POST Strapi: %v", err)
-			continue
+			return fmt.Errorf("recommandations TMDB (page %d): %w", recPage, err)
 		}
 
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+os.Getenv("STRAPI_TOKEN"))
+		// Si aucun résultat sur cette page
+		if len(mr.Results) == 0 {
+			break
+		}
 
-		res, err := http.DefaultClient.Do(req)
-		if err != nil {
-			log.Printf("❌ Erreur envoi POST à Strapi: %v", err)
-			continue
+		for _, rec := range mr.Results {
+			recommendedIDs = append(recommendedIDs, rec.ID)
 		}
-		defer res.Body.Close()
 
-		if res.StatusCode >= 400 {
-			log.Printf("⚠️ Strapi a retourné %d pour Tv Show %d", res.StatusCode, tmdbID)
-		} else {
-			log.Printf("✅ Recommandations insérées pour film %d", tmdbID)
+		if recPage >= mr.TotalPages {
+			break
 		}
 	}
 
-}
+	if len(recommendedIDs) == 0 {
+		log.Printf("ℹ️ Aucune recommandation trouvée pour le Tv Show %d", tmdbID)
+		return nil
+	}
 
-func TvShowRecommendationHandler(w http.ResponseWriter, r *http.Request) {
-	go SyncTvShowsRecommendation()
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "Synchronisation des recommandations de séries TV déclenchée")
+	payload := map[string]interface{}{
+		"id_TvShow":                       tmdbID,
+		"id_TvShow_recommendations":       recommendedIDs,
+		"page_fetched_from_strapi_TvShow": page,
+	}
+
+	if _, _, err := upsertByTMDBID(ctx, strapiRecommendationTvShows, "id_TvShow", tmdbID, payload); err != nil {
+		return fmt.Errorf("strapi a refusé les recommandations: %w", err)
+	}
+	log.Printf("✅ Recommandations enregistrées pour Tv Show %d", tmdbID)
+	return nil
 }
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mon-projet/internal/tmdb"
+)
+
+// searchImportLimit borne le nombre de titres importés en arrière-plan par recherche :
+// une saisie semi-automatique déclenche une recherche à chaque frappe
+const searchImportLimit = 5
+
+// searchResult est un résultat de GET /search, enrichi de sa présence dans Strapi
+type searchResult struct {
+	MediaType     string  `json:"media_type"`
+	ID            int     `json:"id"`
+	Title         string  `json:"title"`
+	OriginalTitle string  `json:"original_title,omitempty"`
+	Overview      string  `json:"overview,omitempty"`
+	Date          string  `json:"date,omitempty"`
+	ImagePath     string  `json:"image_path,omitempty"`
+	Popularity    float64 `json:"popularity,omitempty"`
+	Exists        bool    `json:"exists"`
+	DocumentID    string  `json:"document_id,omitempty"`
+	Importing     bool    `json:"importing,omitempty"`
+}
+
+// searchResponse est la réponse de GET /search
+type searchResponse struct {
+	Query        string         `json:"query"`
+	Type         string         `json:"type"`
+	Page         int            `json:"page"`
+	TotalPages   int            `json:"total_pages"`
+	TotalResults int            `json:"total_results"`
+	Results      []searchResult `json:"results"`
+}
+
+// SearchHandler traite GET /search?q=&type=movie|tv|person|multi&page=&import=true :
+// recherche TMDB, chaque résultat indiquant s'il existe déjà dans Strapi. Avec import=true,
+// les films, séries et personnes absents sont importés en arrière-plan.
+func SearchHandler(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeJSONError(w, http.StatusBadRequest, "paramètre q manquant")
+		return
+	}
+	searchType := r.URL.Query().Get("type")
+	switch searchType {
+	case "":
+		searchType = tmdb.SearchMulti
+	case tmdb.MediaMovie, tmdb.MediaTV, tmdb.MediaPerson, tmdb.SearchMulti:
+	default:
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("type %q inconnu (movie, tv, person ou multi)", searchType))
+		return
+	}
+	page := 1
+	if v := r.URL.Query().Get("page"); v != "" {
+		var err error
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("page invalide: %q", v))
+			return
+		}
+	}
+	autoImport, _ := strconv.ParseBool(r.URL.Query().Get("import"))
+
+	mp, err := tmdbClient.Search(r.Context(), searchType, query, page)
+	if err != nil {
+		log.Printf("❌ Recherche TMDB %q: %v", query, err)
+		writeJSONError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	results, err := searchResults(r.Context(), mp.Results)
+	if err != nil {
+		log.Printf("❌ Recherche %q : lecture Strapi: %v", query, err)
+		writeJSONError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if autoImport {
+		importMissing(mp.Results, results)
+	}
+
+	writeJSON(w, http.StatusOK, searchResponse{
+		Query:        query,
+		Type:         searchType,
+		Page:         mp.Page,
+		TotalPages:   mp.TotalPages,
+		TotalResults: mp.TotalResults,
+		Results:      results,
+	})
+}
+
+// searchResults convertit les résultats TMDB et vérifie leur présence dans Strapi
+// avec une lecture groupée par type (voir ExistsMany)
+func searchResults(ctx context.Context, items []tmdb.MediaItem) ([]searchResult, error) {
+	ids := map[string][]int{}
+	for _, item := range items {
+		ids[item.MediaType] = append(ids[item.MediaType], item.ID())
+	}
+	stored := map[string]map[int]string{}
+	for mediaType, typeIDs := range ids {
+		name, field := searchCollection(mediaType)
+		found, err := ExistsMany(ctx, name, field, typeIDs)
+		if err != nil {
+			return nil, err
+		}
+		stored[mediaType] = found
+	}
+
+	results := make([]searchResult, 0, len(items))
+	for _, item := range items {
+		res := searchResult{MediaType: item.MediaType, ID: item.ID()}
+		switch {
+		case item.Movie != nil:
+			m := item.Movie
+			res.Title, res.OriginalTitle, res.Overview = m.Title, m.OriginalTitle, m.Overview
+			res.Date, res.ImagePath, res.Popularity = m.ReleaseDate, m.PosterPath, m.Popularity
+		case item.TV != nil:
+			t := item.TV
+			res.Title, res.OriginalTitle, res.Overview = t.Name, t.OriginalName, t.Overview
+			res.Date, res.ImagePath, res.Popularity = t.FirstAirDate, t.PosterPath, t.Popularity
+		case item.Person != nil:
+			res.Title, res.ImagePath = item.Person.Name, item.Person.ProfilePath
+		}
+		res.DocumentID, res.Exists = stored[item.MediaType][res.ID]
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// searchCollection renvoie la collection Strapi et le champ d'identifiant TMDB d'un type de résultat
+func searchCollection(mediaType string) (name, field string) {
+	if mediaType == tmdb.MediaPerson {
+		return strapiPeople, "id_person"
+	}
+	return mediaCollection(mediaType)
+}
+
+// searchImports retient les imports en cours pour ne pas relancer le même à chaque frappe
+var searchImports sync.Map
+
+// importMissing importe en arrière-plan les searchImportLimit premiers résultats absents
+// de Strapi et les marque importing dans la réponse
+func importMissing(items []tmdb.MediaItem, results []searchResult) {
+	started := 0
+	for i, item := range items {
+		if results[i].Exists || started >= searchImportLimit {
+			continue
+		}
+		key := item.MediaType + ":" + strconv.Itoa(item.ID())
+		if _, running := searchImports.LoadOrStore(key, true); running {
+			results[i].Importing = true
+			continue
+		}
+		started++
+		results[i].Importing = true
+
+		go func(item tmdb.MediaItem) {
+			defer searchImports.Delete(key)
+			ctx := context.Background()
+			var err error
+			switch item.MediaType {
+			case tmdb.MediaMovie:
+				_, _, err = ImportFilm(ctx, item.ID(), ImportOptions{})
+			case tmdb.MediaTV:
+				_, _, err = ImportTvShow(ctx, item.ID(), ImportOptions{})
+			case tmdb.MediaPerson:
+				_, err = upsertPeople(ctx, []tmdb.Person{*item.Person})
+			}
+			if err != nil {
+				log.Printf("❌ Import depuis la recherche %s: %v", key, err)
+				return
+			}
+			log.Printf("✅ Import depuis la recherche %s terminé", key)
+		}(item)
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// strapiTitleLists est la collection Strapi des entrées de listes : une ligne par
+// (list_name, snapshot_date, rank), reliée au film (film) ou à la série (tv_show) classé
+const strapiTitleLists = "title-lists"
+
+// titleList est une liste TMDB synchronisée : name est le list_name stocké dans Strapi
+type titleList struct {
+	name  string
+	fetch func(ctx context.Context, page int) (*tmdb.MediaPage, error)
+}
+
+// trending renvoie la liste des tendances de mediaType ("all", MediaMovie ou MediaTV) sur window
+func trending(mediaType, window string) titleList {
+	return titleList{
+		name: "trending-" + mediaType + "-" + window,
+		fetch: func(ctx context.Context, page int) (*tmdb.MediaPage, error) {
+			return tmdbClient.Trending(ctx, mediaType, window, page)
+		},
+	}
+}
+
+// namedList renvoie la liste list de mediaType (ex: MediaMovie, ListNowPlaying → "movie-now_playing")
+func namedList(mediaType, list string) titleList {
+	return titleList{
+		name: mediaType + "-" + list,
+		fetch: func(ctx context.Context, page int) (*tmdb.MediaPage, error) {
+			return tmdbClient.TitleList(ctx, mediaType, list, page)
+		},
+	}
+}
+
+// titleLists sont les listes synchronisées par SyncTitleLists
+var titleLists = []titleList{
+	trending("all", tmdb.TrendingDay),
+	trending("all", tmdb.TrendingWeek),
+	trending(tmdb.MediaMovie, tmdb.TrendingDay),
+	trending(tmdb.MediaMovie, tmdb.TrendingWeek),
+	trending(tmdb.MediaTV, tmdb.TrendingDay),
+	trending(tmdb.MediaTV, tmdb.TrendingWeek),
+	namedList(tmdb.MediaMovie, tmdb.ListNowPlaying),
+	namedList(tmdb.MediaMovie, tmdb.ListUpcoming),
+	namedList(tmdb.MediaMovie, tmdb.ListTopRated),
+	namedList(tmdb.MediaTV, tmdb.ListOnTheAir),
+	namedList(tmdb.MediaTV, tmdb.ListAiringToday),
+}
+
+// SyncTitleLists enregistre l'état du jour de chaque liste (tendances, à l'affiche, à venir,
+// mieux notés, en cours de diffusion). Les titres absents de Strapi sont créés par le même
+// chemin que SyncMovies et SyncTvShows (upsertFilm, upsertTvShow) puis enrichis.
+func SyncTitleLists() {
+	ctx := context.Background()
+	snapshot := time.Now().UTC().Format("2006-01-02")
+	for _, l := range titleLists {
+		if err := syncTitleList(ctx, l, snapshot); err != nil {
+			log.Printf("❌ Liste %s : %v", l.name, err)
+		}
+	}
+}
+
+// listEntry est un titre classé dans une liste
+type listEntry struct {
+	rank      int
+	mediaType string
+	doc       strapi.Document
+}
+
+// syncTitleList lit les pages de l, crée ou met à jour les titres classés puis écrit
+// le classement du jour (upsert par rang, les rangs en trop sont supprimés)
+func syncTitleList(ctx context.Context, l titleList, snapshot string) error {
+	var entries []listEntry
+	var stats SyncStats
+	toEnrich := map[string][]enrichTarget{}
+	rank := 0
+
+	for page := 1; page <= syncConfig.Lists.Pages; page++ {
+		mp, err := l.fetch(ctx, page)
+		if err != nil {
+			return fmt.Errorf("TMDB page %d: %w", page, err)
+		}
+		for _, item := range mp.Results {
+			// Les personnes de /trending/all ne sont pas classées
+			if item.MediaType != tmdb.MediaMovie && item.MediaType != tmdb.MediaTV {
+				continue
+			}
+			// Le rang est celui de TMDB, même si un titre précédent n'a pas pu être enregistré
+			rank++
+			var res upsertResult
+			var doc strapi.Document
+			if item.MediaType == tmdb.MediaTV {
+				res, doc, err = upsertTvShow(ctx, *item.TV, 0, titleRelations{})
+			} else {
+				res, doc, err = upsertFilm(ctx, *item.Movie, 0, titleRelations{})
+			}
+			if err != nil {
+				log.Printf("❌ Liste %s : upsert %s %d: %v", l.name, item.MediaType, item.ID(), err)
+				stats.Failed++
+				continue
+			}
+			stats.add(res)
+			entries = append(entries, listEntry{rank: rank, mediaType: item.MediaType, doc: doc})
+			if needsEnrichment(res, doc) {
+				toEnrich[item.MediaType] = append(toEnrich[item.MediaType], enrichTarget{tmdbID: item.ID(), doc: doc})
+			}
+		}
+		if page >= mp.TotalPages {
+			break
+		}
+	}
+	log.Printf("📊 Liste %s : titres %s", l.name, stats)
+
+	if n := enrichMovies(ctx, toEnrich[tmdb.MediaMovie]) + enrichTvShows(ctx, toEnrich[tmdb.MediaTV]); n > 0 {
+		log.Printf("⚠️ Liste %s : détail de %d titres non enregistré", l.name, n)
+	}
+
+	stored, err := collection[strapi.Document](strapiTitleLists).All(ctx,
+		strapi.NewQuery().Eq("list_name", l.name).Eq("snapshot_date", snapshot).Populate("film", "tv_show"))
+	if err != nil {
+		return fmt.Errorf("lecture du classement: %w", err)
+	}
+	byRank := make(map[int]strapi.Document, len(stored))
+	for _, doc := range stored {
+		if rank, err := strconv.Atoi(toString(doc["rank"])); err == nil {
+			byRank[rank] = doc
+		}
+	}
+
+	for _, e := range entries {
+		payload := map[string]interface{}{
+			"list_name":     l.name,
+			"snapshot_date": snapshot,
+			"rank":          e.rank,
+			"media_type":    e.mediaType,
+			"film":          relationSet{},
+			"tv_show":       relationSet{},
+		}
+		payload[titleRelationField(e.mediaType)] = relationSet{e.doc.DocumentID()}
+
+		if doc, ok := byRank[e.rank]; ok {
+			delete(byRank, e.rank)
+			if _, _, err := updateDocument(ctx, strapiTitleLists, doc, payload); err != nil {
+				return fmt.Errorf("rang %d: %w", e.rank, err)
+			}
+			continue
+		}
+		if _, err := collection[strapi.Document](strapiTitleLists).Create(ctx, payload); err != nil {
+			return fmt.Errorf("rang %d: %w", e.rank, err)
+		}
+	}
+	for rank, doc := range byRank {
+		if err := collection[strapi.Document](strapiTitleLists).Delete(ctx, doc.DocumentID()); err != nil {
+			return fmt.Errorf("suppression du rang %d: %w", rank, err)
+		}
+	}
+	log.Printf("✅ Liste %s du %s : %d titres classés", l.name, snapshot, len(entries))
+	return nil
+}
+
+func TitleListsHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "title-lists", "Synchronisation des listes (tendances, à l'affiche, à venir…) déclenchée")
+}
@@ -1,154 +1,103 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
 	"log"
 	"net/http"
-	"os"
+	"net/url"
 
-	"github.com/joho/godotenv"
-	cron "github.com/robfig/cron/v3"
+	"mon-projet/internal/config"
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
 )
 
-var (
-	tmdbTvShowURL   string
-	strapiTvShowURL string
-)
-
-type TMDBTvShow struct {
-	ID               int      `json:"id"`
-	Adult            bool     `json:"adult"`
-	BackdropPath     string   `json:"backdrop_path"`
-	OriginalName     string   `json:"original_name"`
-	OriginalLanguage string   `json:"original_language"`
-	Overview         string   `json:"overview"`
-	PosterPath       string   `json:"poster_path"`
-	ReleaseDate      string   `json:"release_date"`
-	Name             string   `json:"name"`
-	FirstAirDate     string   `json:"first_air_date"`
-	VoteAverage      float64  `json:"vote_average"`
-	VoteCount        int      `json:"vote_count"`
-	Popularity       float64  `json:"popularity"`
-	GenreIDs         []int    `json:"genre_ids"`
-	OriginCountry    []string `json:"origin_country"`
-}
+// strapiTvShows est la collection Strapi des séries TV
+const strapiTvShows = "tv-shows"
 
-type TvShowResponse struct {
-	Page       int          `json:"page"`
-	Results    []TMDBTvShow `json:"results"`
-	TotalPages int          `json:"total_pages"`
-}
+// TMDBTvShow représente une série TV renvoyée par TMDB
+type TMDBTvShow = tmdb.TVShow
 
-func init() {
-	tmdbTvShowURL = "https://api.themoviedb.org/3/discover/tv"
-	strapiTvShowURL = os.Getenv("STRAPI_URL") + "/api/tv-shows"
+// TvShowResponse enveloppe la réponse TMDB pour discover/tv
+type TvShowResponse = tmdb.TVPage
 
-	if err := godotenv.Load(); err != nil {
-		log.Printf("⚠️ .env non chargé: %v", err)
+func SyncTvShows() {
+	ctx := context.Background()
+	for _, p := range syncConfig.TvShows.Pipelines {
+		syncTvShowsPipeline(ctx, p)
 	}
+}
 
-	c := cron.New()
-	_, err := c.AddFunc("0 * * * *", func() {
-		log.Println("🚀 Lancement planifié: SyncTvShows chaque une heure")
-		SyncTvShows()
-	})
+// syncTvShowsPipeline avance d'une page le pipeline discover p, avec son propre checkpoint
+func syncTvShowsPipeline(ctx context.Context, p config.Pipeline) {
+	job := pipelineJob("tv-shows", p)
+	var legacy func() int
+	if p.Name == config.DefaultPipeline {
+		legacy = func() int { return getLastFetchedPage(ctx, strapiTvShows, "page_fetched_from") }
+	}
+	cp, err := loadCheckpoint(ctx, job, legacy)
 	if err != nil {
-		log.Fatalf("Erreur planification cron: %v", err)
+		log.Printf("❌ Lecture du checkpoint %s: %v", job, err)
+		return
+	}
+	params := p.Discover.Params(tmdb.MediaTV)
+
+	// On retente d'abord une page en échec, puis la page suivante
+	for _, page := range cp.Pending() {
+		recordPage(&cp, page, syncTvShowsPage(ctx, params, page))
 	}
-	c.Start()
+	saveCheckpoint(ctx, cp)
 }
 
-func SyncTvShows() {
-	lastPage := getLastFetchedPage(strapiTvShowURL)
-	nextPage := lastPage + 1
-    log.Printf("🔄 Sync TV shows : récupération de la page %d depuis TMDB", nextPage)
+// syncTvShowsPage synchronise une page discover/tv.
+// Une page au-delà de la dernière page TMDB est pageEmpty, une page illisible pageUnread ;
+// une page n'est pageDone que si tous ses titres ont été insérés ou mis à jour.
+func syncTvShowsPage(ctx context.Context, params url.Values, nextPage int) pageOutcome {
+	log.Printf("🔄 Sync TV shows : récupération de la page %d depuis TMDB", nextPage)
 
-	tmdbURL := fmt.Sprintf("%s?api_key=%s&language=fr-FR&page=%d", tmdbTvShowURL, os.Getenv("API_KEY"), nextPage)
-	resp, err := http.Get(tmdbURL)
+	tsr, err := tmdbClient.DiscoverTV(ctx, nextPage, params)
 	if err != nil {
-		log.Printf("❌ Erreur TMDB GET: %v", err)
-		return
-	}
-	defer resp.Body.Close()
-
-	var tsr TvShowResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tsr); err != nil {
-		log.Printf("❌ JSON decode TMDB: %v", err)
-		return
+		log.Printf("❌ Erreur TMDB discover/tv: %v", err)
+		return pageUnread
 	}
 
 	/* J'ai ajouté cette condition pour vérifier si on a atteint la fin de l'API. */
 	if len(tsr.Results) == 0 {
 		log.Printf("✅ Plus de Tv-show à synchroniser. Toutes les pages TMDB sont terminées.")
-		return
+		return pageEmpty
 	}
 
 	log.Printf("📦 TMDB page %d: %d Tv-Show, total pages %d", tsr.Page, len(tsr.Results), tsr.TotalPages)
 
 	allSuccess := true
-	endpoint := strapiTvShowURL + "?filters[id_TvShow][$eq]"
+	var stats SyncStats
+	var toEnrich []enrichTarget
 
 	for _, m := range tsr.Results {
-		exists, err := Exists(m.ID, endpoint)
+		res, doc, err := upsertTvShow(ctx, m, nextPage, titleRelations{})
 		if err != nil {
-           log.Printf("⚠️ Erreur lors de la vérification de l’existence pour l’ID %d : %v", m.ID, err)
-			continue
-		}
-		if exists {
-			log.Printf("ℹ️ Tv-Show existant, skip: %s (%d)", m.Name, m.ID)
-			continue
-		}
-			firstAirDate := ""
-			if m.FirstAirDate != "" && len(m.FirstAirDate) >= 10 {
-			firstAirDate = m.FirstAirDate[:10]
-			}
-
-		payload := map[string]interface{}{"data": map[string]interface{}{
-			"id_TvShow":            m.ID,
-			"Name":                 m.Name,
-			"original_Name":        m.OriginalName,
-			"original_language":    m.OriginalLanguage,
-			"overview":             m.Overview,
-			"backdrop_path":        m.BackdropPath,
-			"poster_path":          m.PosterPath,
-			"Origin_country":       m.OriginCountry,
-			"first_air_date":       firstAirDate,
-			"vote_average_tmdb":    m.VoteAverage,
-			"vote_count_tmdb":      m.VoteCount,
-			"popularity_tmdb":      m.Popularity,
-			"genre_tv_films":       m.GenreIDs,
-			"adult":                m.Adult,
-			"popularity_website":   0.0,
-			"vote_average_website": 0.0,
-			"vote_count_website":   0.0,
-			"page_fetched_from":    nextPage,
-		}}
-
-
-		b, _ := json.Marshal(payload)
-		req, _ := http.NewRequest("POST", strapiTvShowURL, bytes.NewBuffer(b))
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+strapiToken)
-
-		res, err := http.DefaultClient.Do(req)
-		if err != nil {
-			log.Printf("❌ POST Strapi film %d: %v", m.ID, err)
+			log.Printf("❌ Upsert Strapi Tv-Show %d: %v", m.ID, err)
 			allSuccess = false
+			stats.Failed++
 			continue
 		}
-		res.Body.Close()
-
-
-		if res.StatusCode >= 400 {
-			log.Printf("⚠️ Strapi returned %d for Tv-Show %d", res.StatusCode, m.ID)
-			allSuccess = false
-		} else {
+		stats.add(res)
+		switch res {
+		case upsertInserted:
 			log.Printf("✅ Tv-Show inséré: %s (%d)", m.Name, m.ID)
+		case upsertUpdated:
+			log.Printf("🔄 Tv-Show mis à jour: %s (%d)", m.Name, m.ID)
+		}
+		if needsEnrichment(res, doc) {
+			toEnrich = append(toEnrich, enrichTarget{tmdbID: m.ID, doc: doc})
 		}
+	}
 
+	log.Printf("📊 Tv-Shows page %d : %s", nextPage, stats)
 
+	// Détail complet, saisons et épisodes des séries nouvelles ou modifiées
+	if failed := enrichTvShows(ctx, toEnrich); failed > 0 {
+		log.Printf("⚠️ Détail de %d/%d séries de la page %d non enregistré", failed, len(toEnrich), nextPage)
+		allSuccess = false
 	}
 
 	// Si tous les Tv-Show ont été correctement insérés, on peut dire que la page est traitée
@@ -157,12 +106,76 @@ func SyncTvShows() {
 	} else {
 		log.Printf("✅ Tous les Tv Shows de la page %d ont été insérés avec succès.", nextPage)
 	}
+	if !allSuccess {
+		return pageFailed
+	}
+	return pageDone
+}
+
+// upsertTvShow enregistre la série m (insertion ou mise à jour des champs TMDB modifiés)
+// avec ses traductions et les relations connues de rel (les genres sont résolus ici).
+// C'est le chemin d'écriture commun à toutes les synchronisations de séries.
+func upsertTvShow(ctx context.Context, m TMDBTvShow, page int, rel titleRelations) (upsertResult, strapi.Document, error) {
+	var err error
+	if rel.Genres, err = resolveGenres(ctx, m.GenreIDs); err != nil {
+		return 0, nil, err
+	}
+	payload := tvShowPayload(m, page, rel)
+	var translations map[string]titleTranslation
+	if rel.Translations != nil {
+		translations = titleTranslations(rel.Translations, m.OriginalName, m.Overview)
+		addLocaleFields(payload, "Name", translations)
+	}
 
+	res, doc, err := upsertByTMDBID(ctx, strapiTvShows, "id_TvShow", m.ID, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	doc, err = upsertTitleTranslations(ctx, strapiTvShows, "Name", res, doc, translations, func() (map[string]titleTranslation, error) {
+		return fetchTitleTranslations(ctx, tmdb.MediaTV, m.ID, m.OriginalName, m.Overview)
+	})
+	return res, doc, err
 }
 
+// tvShowPayload construit le document Strapi d'une série TMDB (locale principale).
+// Un nom vide dans la locale principale retombe sur original_name.
+// genre_tv_films est une relation vers la collection des genres (voir resolveGenres),
+// keywords n'est envoyé que si les mots-clés sont connus (voir titleRelations).
+// Les champs *_website et page_fetched_from ne sont pris en compte qu'à l'insertion (voir upsertByTMDBID)
+func tvShowPayload(m TMDBTvShow, page int, rel titleRelations) map[string]interface{} {
+	firstAirDate := ""
+	if m.FirstAirDate != "" && len(m.FirstAirDate) >= 10 {
+		firstAirDate = m.FirstAirDate[:10]
+	}
+	name := m.Name
+	if name == "" {
+		name = m.OriginalName
+	}
+
+	payload := map[string]interface{}{
+		"id_TvShow":            m.ID,
+		"Name":                 name,
+		"original_Name":        m.OriginalName,
+		"original_language":    m.OriginalLanguage,
+		"overview":             m.Overview,
+		"backdrop_path":        m.BackdropPath,
+		"poster_path":          m.PosterPath,
+		"Origin_country":       m.OriginCountry,
+		"first_air_date":       firstAirDate,
+		"vote_average_tmdb":    m.VoteAverage,
+		"vote_count_tmdb":      m.VoteCount,
+		"popularity_tmdb":      m.Popularity,
+		"genre_tv_films":       rel.Genres,
+		"adult":                m.Adult,
+		"popularity_website":   0.0,
+		"vote_average_website": 0.0,
+		"vote_count_website":   0.0,
+		"page_fetched_from":    page,
+	}
+	rel.add(payload)
+	return payload
+}
 
 func TvShowHandler(w http.ResponseWriter, r *http.Request) {
-	go SyncTvShows()
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "Synchronisation des séries TV déclenchée")
+	triggerJob(w, "tv-shows", "Synchronisation des séries TV déclenchée")
 }
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"mon-projet/internal/config"
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// Collections Strapi des saisons et épisodes, liées aux séries (tv_show) et aux saisons (season)
+const (
+	strapiSeasons  = "seasons"
+	strapiEpisodes = "episodes"
+)
+
+// tvDetailsAppend liste les sous-ressources demandées avec le détail d'une série
+var tvDetailsAppend = []string{"translations", "keywords"}
+
+// enrichTvShows récupère /tv/{id} pour chaque série, enregistre ses champs de détail puis ses
+// saisons et épisodes, par lots (voir runBatched). Renvoie le nombre de séries en échec.
+func enrichTvShows(ctx context.Context, targets []enrichTarget) int {
+	return runBatched(ctx, targets, func(t enrichTarget) error {
+		details, err := tmdbClient.TVDetails(ctx, t.tmdbID, tvDetailsAppend...)
+		if err != nil {
+			return fmt.Errorf("détail TMDB de la série %d: %w", t.tmdbID, err)
+		}
+		if err := enrichTvShow(ctx, t.doc, details); err != nil {
+			return fmt.Errorf("détail Strapi de la série %d: %w", t.tmdbID, err)
+		}
+		return enrichKeywords(ctx, tmdb.MediaTV, t, details.Keywords)
+	})
+}
+
+// enrichTvShow écrit les champs de détail d'une série déjà stockée (doc), puis ses saisons et épisodes
+func enrichTvShow(ctx context.Context, doc strapi.Document, d *tmdb.TVDetails) error {
+	if err := syncSeasons(ctx, doc.DocumentID(), d); err != nil {
+		return err
+	}
+
+	payload := tvDetailsPayload(d)
+	localized := map[string]map[string]interface{}{}
+	for _, locale := range secondaryLocales() {
+		tagline := d.Tagline
+		if d.Translations != nil {
+			if tr := d.Translations.Find(locale); tr != nil && tr.Tagline != "" {
+				tagline = tr.Tagline
+			}
+		}
+		localized[locale] = map[string]interface{}{"tagline": tagline}
+		if syncConfig.LocaleMode == config.LocaleModeFields {
+			payload["tagline_"+localeSuffix(locale)] = tagline
+		}
+	}
+
+	// details_synced_at n'est écrit qu'une fois les saisons enregistrées
+	if _, _, err := updateDocument(ctx, strapiTvShows, doc, payload); err != nil {
+		return err
+	}
+	return writeLocalizations(ctx, strapiTvShows, doc.DocumentID(), localized)
+}
+
+// tvDetailsPayload construit les champs de détail d'une série (/tv/{id})
+func tvDetailsPayload(d *tmdb.TVDetails) map[string]interface{} {
+	return map[string]interface{}{
+		"number_of_seasons":   d.NumberOfSeasons,
+		"number_of_episodes":  d.NumberOfEpisodes,
+		"status":              d.Status,
+		"tagline":             d.Tagline,
+		"homepage":            d.Homepage,
+		"in_production":       d.InProduction,
+		"networks":            d.Networks,
+		"created_by":          d.CreatedBy,
+		"episode_run_time":    d.EpisodeRunTime,
+		"last_episode_to_air": d.LastEpisodeToAir,
+		"next_episode_to_air": d.NextEpisodeToAir,
+		"details_synced_at":   time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// syncSeasons enregistre les saisons de la série et, pour celles qui sont nouvelles, dont le
+// nombre d'épisodes a changé ou qui sont en cours de diffusion, relit /tv/{id}/season/{n}
+// pour enregistrer les épisodes.
+func syncSeasons(ctx context.Context, showDocumentID string, d *tmdb.TVDetails) error {
+	seasonIDs := make([]int, 0, len(d.Seasons))
+	for _, s := range d.Seasons {
+		seasonIDs = append(seasonIDs, s.ID)
+	}
+	stored, err := findManyByTMDBID(ctx, strapiSeasons, "id_season", seasonIDs)
+	if err != nil {
+		return fmt.Errorf("lecture des saisons: %w", err)
+	}
+
+	airing := map[int]bool{}
+	for _, e := range []*tmdb.EpisodeSummary{d.LastEpisodeToAir, d.NextEpisodeToAir} {
+		if e != nil {
+			airing[e.SeasonNumber] = true
+		}
+	}
+
+	for _, summary := range d.Seasons {
+		doc, exists := stored[summary.ID]
+		refresh := !exists || airing[summary.SeasonNumber] || toString(doc["episode_count"]) != strconv.Itoa(summary.EpisodeCount)
+
+		payload := map[string]interface{}{
+			"id_season":     summary.ID,
+			"season_number": summary.SeasonNumber,
+			"name":          summary.Name,
+			"overview":      summary.Overview,
+			"air_date":      summary.AirDate,
+			"poster_path":   summary.PosterPath,
+			"episode_count": summary.EpisodeCount,
+		}
+		if exists {
+			// La relation tv_show est posée à la création et ne change pas
+			if _, doc, err = updateDocument(ctx, strapiSeasons, doc, payload, "id_season"); err != nil {
+				return fmt.Errorf("saison %d: %w", summary.SeasonNumber, err)
+			}
+		} else {
+			payload["tv_show"] = relationSet{showDocumentID}
+			created, err := collection[strapi.Document](strapiSeasons).Create(ctx, payload)
+			if err != nil {
+				return fmt.Errorf("saison %d: %w", summary.SeasonNumber, err)
+			}
+			doc = derefDocument(created)
+		}
+
+		if !refresh {
+			continue
+		}
+		season, err := tmdbClient.TVSeason(ctx, d.ID, summary.SeasonNumber)
+		if err != nil {
+			return fmt.Errorf("saison TMDB %d: %w", summary.SeasonNumber, err)
+		}
+		if err := syncEpisodes(ctx, showDocumentID, doc.DocumentID(), season.Episodes); err != nil {
+			return fmt.Errorf("épisodes de la saison %d: %w", summary.SeasonNumber, err)
+		}
+	}
+	return nil
+}
+
+// syncEpisodes crée ou met à jour les épisodes d'une saison (upsert par id_episode)
+func syncEpisodes(ctx context.Context, showDocumentID, seasonDocumentID string, episodes []tmdb.Episode) error {
+	ids := make([]int, 0, len(episodes))
+	for _, e := range episodes {
+		ids = append(ids, e.ID)
+	}
+	stored, err := findManyByTMDBID(ctx, strapiEpisodes, "id_episode", ids)
+	if err != nil {
+		return err
+	}
+
+	var stats SyncStats
+	for _, e := range episodes {
+		payload := map[string]interface{}{
+			"id_episode":        e.ID,
+			"episode_number":    e.EpisodeNumber,
+			"season_number":     e.SeasonNumber,
+			"name":              e.Name,
+			"overview":          e.Overview,
+			"air_date":          e.AirDate,
+			"runtime":           e.Runtime,
+			"still_path":        e.StillPath,
+			"vote_average_tmdb": e.VoteAverage,
+			"vote_count_tmdb":   e.VoteCount,
+		}
+		if doc, ok := stored[e.ID]; ok {
+			res, _, err := updateDocument(ctx, strapiEpisodes, doc, payload, "id_episode")
+			if err != nil {
+				return fmt.Errorf("épisode %d: %w", e.EpisodeNumber, err)
+			}
+			stats.add(res)
+			continue
+		}
+		payload["tv_show"] = relationSet{showDocumentID}
+		payload["season"] = relationSet{seasonDocumentID}
+		if _, err := collection[strapi.Document](strapiEpisodes).Create(ctx, payload); err != nil {
+			return fmt.Errorf("épisode %d: %w", e.EpisodeNumber, err)
+		}
+		stats.add(upsertInserted)
+	}
+	log.Printf("📺 Épisodes : %s", stats)
+	return nil
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"mon-projet/internal/config"
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// strapiVideos est la collection Strapi des vidéos, liées à un film (film) ou à une série (tv_show)
+const strapiVideos = "videos"
+
+// SyncVideos parcourt les films et séries stockés (checkpoints videos:movie et videos:tv),
+// enregistre toutes leurs vidéos dans la collection videos et la meilleure bande-annonce
+// de chaque locale sur le titre (trailer_key, trailer_site)
+func SyncVideos() {
+	ctx := context.Background()
+	syncStoredTitles(ctx, "videos:movie", tmdb.MediaMovie, syncConfig.Enrichment.PagesPerRun, syncTitleVideos)
+	syncStoredTitles(ctx, "videos:tv", tmdb.MediaTV, syncConfig.Enrichment.PagesPerRun, syncTitleVideos)
+}
+
+// syncTitleVideos enregistre les vidéos d'un titre (upsert par id_video), supprime celles que
+// TMDB ne renvoie plus puis met à jour la bande-annonce du titre
+func syncTitleVideos(ctx context.Context, t storedTitle) error {
+	// Une vidéo par langue configurée, plus celles sans langue
+	languages := []string{"null"}
+	for _, locale := range locales() {
+		languages = append(languages, videoLanguage(locale))
+	}
+	videos, err := tmdbClient.Videos(ctx, t.mediaType, t.tmdbID, languages...)
+	if err != nil {
+		return fmt.Errorf("vidéos TMDB: %w", err)
+	}
+
+	relation := titleRelationField(t.mediaType)
+	stored, err := collection[strapi.Document](strapiVideos).All(ctx, strapi.NewQuery().Related(relation, t.doc))
+	if err != nil {
+		return fmt.Errorf("lecture des vidéos: %w", err)
+	}
+	byID := make(map[string]strapi.Document, len(stored))
+	for _, doc := range stored {
+		byID[toString(doc["id_video"])] = doc
+	}
+
+	var stats SyncStats
+	for _, v := range videos.Results {
+		payload := videoPayload(v)
+		if doc, ok := byID[v.ID]; ok {
+			delete(byID, v.ID)
+			res, _, err := updateDocument(ctx, strapiVideos, doc, payload, "id_video")
+			if err != nil {
+				return fmt.Errorf("vidéo %s: %w", v.ID, err)
+			}
+			stats.add(res)
+			continue
+		}
+		payload[relation] = relationSet{t.doc.DocumentID()}
+		if _, err := collection[strapi.Document](strapiVideos).Create(ctx, payload); err != nil {
+			return fmt.Errorf("vidéo %s: %w", v.ID, err)
+		}
+		stats.add(upsertInserted)
+	}
+	for id, doc := range byID {
+		if err := collection[strapi.Document](strapiVideos).Delete(ctx, doc.DocumentID()); err != nil {
+			return fmt.Errorf("suppression de la vidéo %s: %w", id, err)
+		}
+	}
+	log.Printf("🎬 Vidéos %s %d : %s, %d supprimées", t.mediaType, t.tmdbID, stats, len(byID))
+
+	return writeTrailers(ctx, t, videos.Results)
+}
+
+// writeTrailers écrit la meilleure bande-annonce de chaque locale sur le titre :
+// champs trailer_key/trailer_site pour la locale principale, suffixés (mode "fields")
+// ou localisations Strapi (mode "i18n") pour les autres
+func writeTrailers(ctx context.Context, t storedTitle, videos []tmdb.Video) error {
+	name, _ := mediaCollection(t.mediaType)
+
+	payload := trailerFields(bestTrailer(videos, locales()[0]), "")
+	localized := map[string]map[string]interface{}{}
+	for _, locale := range secondaryLocales() {
+		best := bestTrailer(videos, locale)
+		localized[locale] = trailerFields(best, "")
+		if syncConfig.LocaleMode == config.LocaleModeFields {
+			for key, value := range trailerFields(best, "_"+localeSuffix(locale)) {
+				payload[key] = value
+			}
+		}
+	}
+
+	if _, _, err := updateDocument(ctx, name, t.doc, payload); err != nil {
+		return fmt.Errorf("bande-annonce: %w", err)
+	}
+	return writeLocalizations(ctx, name, t.doc.DocumentID(), localized)
+}
+
+func trailerFields(v *tmdb.Video, suffix string) map[string]interface{} {
+	key, site := "", ""
+	if v != nil {
+		key, site = v.Key, v.Site
+	}
+	return map[string]interface{}{
+		"trailer_key" + suffix:  key,
+		"trailer_site" + suffix: site,
+	}
+}
+
+// bestTrailer choisit la vidéo à afficher pour locale parmi celles de sa langue :
+// une bande-annonce (Trailer) plutôt qu'un teaser, officielle, sur YouTube, puis la plus récente.
+// Si aucune vidéo n'est dans la langue de la locale, le choix se fait parmi toutes les vidéos.
+func bestTrailer(videos []tmdb.Video, locale string) *tmdb.Video {
+	lang := videoLanguage(locale)
+	var best *tmdb.Video
+	for _, sameLanguage := range []bool{true, false} {
+		for i := range videos {
+			v := &videos[i]
+			if sameLanguage && !strings.EqualFold(v.ISO639_1, lang) {
+				continue
+			}
+			if videoTypeRank(v) == 0 {
+				continue
+			}
+			if best == nil || compareTrailers(v, best) > 0 {
+				best = v
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
+
+// compareTrailers compare deux vidéos candidates critère par critère : type, officielle,
+// YouTube, puis date de publication. Un critère n'est départagé par le suivant qu'en cas
+// d'égalité, pour qu'un teaser ne passe jamais devant une bande-annonce.
+func compareTrailers(a, b *tmdb.Video) int {
+	return cmp.Or(
+		cmp.Compare(videoTypeRank(a), videoTypeRank(b)),
+		cmp.Compare(boolRank(a.Official), boolRank(b.Official)),
+		cmp.Compare(boolRank(a.Site == "YouTube"), boolRank(b.Site == "YouTube")),
+		strings.Compare(a.PublishedAt, b.PublishedAt),
+	)
+}
+
+// videoTypeRank classe les types de vidéos candidates à la bande-annonce ; 0 pour les autres types
+func videoTypeRank(v *tmdb.Video) int {
+	switch v.Type {
+	case "Trailer":
+		return 2
+	case "Teaser":
+		return 1
+	}
+	return 0
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// videoLanguage renvoie le code ISO 639-1 d'une locale (ex: "fr-FR" → "fr")
+func videoLanguage(locale string) string {
+	lang, _, _ := strings.Cut(locale, "-")
+	return strings.ToLower(lang)
+}
+
+// videoPayload construit le document Strapi d'une vidéo TMDB
+func videoPayload(v tmdb.Video) map[string]interface{} {
+	return map[string]interface{}{
+		"id_video":     v.ID,
+		"key":          v.Key,
+		"site":         v.Site,
+		"name":         v.Name,
+		"type":         v.Type,
+		"official":     v.Official,
+		"size":         v.Size,
+		"iso_639_1":    v.ISO639_1,
+		"iso_3166_1":   v.ISO3166_1,
+		"published_at": v.PublishedAt,
+	}
+}
+
+func VideosHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "videos", "Synchronisation des vidéos déclenchée")
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// Collections Strapi des fournisseurs (référence) et de la disponibilité d'un titre dans un pays.
+// Une disponibilité relie un film (film) ou une série (tv_show) à ses fournisseurs par mode d'accès.
+const (
+	strapiWatchProviders      = "watch-providers"
+	strapiWatchAvailabilities = "watch-availabilities"
+)
+
+// watchModes sont les modes d'accès TMDB, chacun stocké comme relation vers watch-providers
+var watchModes = []string{"flatrate", "rent", "buy", "free", "ads"}
+
+// SyncWatchProviderList met à jour la collection de référence des fournisseurs
+// depuis /watch/providers/movie et /watch/providers/tv (upsert par id_provider)
+func SyncWatchProviderList() {
+	ctx := context.Background()
+
+	providers := map[int]map[string]interface{}{}
+	var ids []int
+	for _, mediaType := range []string{tmdb.MediaMovie, tmdb.MediaTV} {
+		list, err := tmdbClient.WatchProviderList(ctx, mediaType, "")
+		if err != nil {
+			log.Printf("❌ Erreur TMDB watch/providers/%s: %v", mediaType, err)
+			return
+		}
+		for _, p := range list {
+			payload, ok := providers[p.ProviderID]
+			if !ok {
+				payload = watchProviderPayload(p)
+				payload["is_movie"] = false
+				payload["is_tv"] = false
+				providers[p.ProviderID] = payload
+				ids = append(ids, p.ProviderID)
+			}
+			payload["is_"+mediaType] = true
+		}
+	}
+
+	stored, err := findManyByTMDBID(ctx, strapiWatchProviders, "id_provider", ids)
+	if err != nil {
+		log.Printf("❌ Lecture des fournisseurs Strapi: %v", err)
+		return
+	}
+	var stats SyncStats
+	for _, id := range ids {
+		var res upsertResult
+		if doc, ok := stored[id]; ok {
+			res, _, err = updateDocument(ctx, strapiWatchProviders, doc, providers[id], "id_provider")
+		} else {
+			res = upsertInserted
+			_, err = collection[strapi.Document](strapiWatchProviders).Create(ctx, providers[id])
+		}
+		if err != nil {
+			log.Printf("❌ Fournisseur %d: %v", id, err)
+			stats.Failed++
+			continue
+		}
+		stats.add(res)
+	}
+	resetProviderCache()
+	log.Printf("📊 Fournisseurs : %s", stats)
+}
+
+// watchProviderPayload construit le document Strapi d'un fournisseur
+func watchProviderPayload(p tmdb.WatchProvider) map[string]interface{} {
+	payload := map[string]interface{}{
+		"id_provider":      p.ProviderID,
+		"provider_name":    p.ProviderName,
+		"logo_path":        p.LogoPath,
+		"display_priority": p.DisplayPriority,
+	}
+	if p.DisplayPriorities != nil {
+		payload["display_priorities"] = p.DisplayPriorities
+	}
+	return payload
+}
+
+// SyncWatchProviders parcourt tous les titres stockés à chaque passage, sans limite de pages
+// (checkpoints watch-providers:movie et watch-providers:tv), et enregistre leur disponibilité
+// dans chaque pays configuré (regions)
+func SyncWatchProviders() {
+	ctx := context.Background()
+	syncStoredTitles(ctx, "watch-providers:movie", tmdb.MediaMovie, allPages, syncTitleWatchProviders)
+	syncStoredTitles(ctx, "watch-providers:tv", tmdb.MediaTV, allPages, syncTitleWatchProviders)
+}
+
+// syncTitleWatchProviders écrit une disponibilité par pays configuré (upsert par pays) et
+// supprime celles des pays où le titre n'est plus proposé
+func syncTitleWatchProviders(ctx context.Context, t storedTitle) error {
+	wp, err := tmdbClient.WatchProviders(ctx, t.mediaType, t.tmdbID)
+	if err != nil {
+		return fmt.Errorf("fournisseurs TMDB: %w", err)
+	}
+
+	relation := titleRelationField(t.mediaType)
+	stored, err := collection[strapi.Document](strapiWatchAvailabilities).All(ctx,
+		strapi.NewQuery().Related(relation, t.doc).Populate(watchModes...))
+	if err != nil {
+		return fmt.Errorf("lecture des disponibilités: %w", err)
+	}
+	byRegion := make(map[string]strapi.Document, len(stored))
+	for _, doc := range stored {
+		byRegion[toString(doc["region"])] = doc
+	}
+
+	for _, region := range syncConfig.Regions {
+		availability, ok := wp.Results[region]
+		if !ok {
+			continue
+		}
+		payload := map[string]interface{}{
+			"region": region,
+			"link":   availability.Link,
+		}
+		for mode, providers := range map[string][]tmdb.WatchProvider{
+			"flatrate": availability.Flatrate,
+			"rent":     availability.Rent,
+			"buy":      availability.Buy,
+			"free":     availability.Free,
+			"ads":      availability.Ads,
+		} {
+			if payload[mode], err = resolveProviders(ctx, providers); err != nil {
+				return err
+			}
+		}
+
+		if doc, ok := byRegion[region]; ok {
+			delete(byRegion, region)
+			if _, _, err := updateDocument(ctx, strapiWatchAvailabilities, doc, payload, "region"); err != nil {
+				return fmt.Errorf("disponibilité %s: %w", region, err)
+			}
+			continue
+		}
+		payload[relation] = relationSet{t.doc.DocumentID()}
+		if _, err := collection[strapi.Document](strapiWatchAvailabilities).Create(ctx, payload); err != nil {
+			return fmt.Errorf("disponibilité %s: %w", region, err)
+		}
+	}
+
+	for region, doc := range byRegion {
+		if err := collection[strapi.Document](strapiWatchAvailabilities).Delete(ctx, doc.DocumentID()); err != nil {
+			return fmt.Errorf("suppression de la disponibilité %s: %w", region, err)
+		}
+	}
+	return nil
+}
+
+// providerCache associe id_provider → documentId Strapi, rechargé quand un fournisseur manque
+var providerCache struct {
+	sync.Mutex
+	ids map[int]string
+}
+
+// resolveProviders convertit des fournisseurs TMDB en relation vers watch-providers.
+// Un fournisseur absent de la référence (pas encore synchronisée) y est créé.
+func resolveProviders(ctx context.Context, providers []tmdb.WatchProvider) (relationSet, error) {
+	providerCache.Lock()
+	defer providerCache.Unlock()
+
+	missing := providerCache.ids == nil
+	for _, p := range providers {
+		if _, ok := providerCache.ids[p.ProviderID]; !ok {
+			missing = true
+			break
+		}
+	}
+	if missing {
+		docs, err := collection[strapi.Document](strapiWatchProviders).All(ctx, strapi.NewQuery().Fields("id_provider"))
+		if err != nil {
+			return nil, fmt.Errorf("lecture des fournisseurs: %w", err)
+		}
+		providerCache.ids = make(map[int]string, len(docs))
+		for _, doc := range docs {
+			if id, err := strconv.Atoi(toString(doc["id_provider"])); err == nil {
+				providerCache.ids[id] = doc.DocumentID()
+			}
+		}
+	}
+
+	set := relationSet{}
+	for _, p := range providers {
+		documentID, ok := providerCache.ids[p.ProviderID]
+		if !ok {
+			created, err := collection[strapi.Document](strapiWatchProviders).Create(ctx, watchProviderPayload(p))
+			if err != nil {
+				return nil, fmt.Errorf("fournisseur %d: %w", p.ProviderID, err)
+			}
+			documentID = derefDocument(created).DocumentID()
+			providerCache.ids[p.ProviderID] = documentID
+		}
+		set = append(set, documentID)
+	}
+	return set, nil
+}
+
+func resetProviderCache() {
+	providerCache.Lock()
+	providerCache.ids = nil
+	providerCache.Unlock()
+}
+
+func WatchProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "watch-providers", "Synchronisation des disponibilités en streaming déclenchée")
+}
+
+func WatchProviderListHandler(w http.ResponseWriter, r *http.Request) {
+	triggerJob(w, "watch-provider-list", "Synchronisation de la liste des fournisseurs déclenchée")
+}
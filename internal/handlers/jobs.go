@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"mon-projet/internal/scheduler"
+)
+
+// syncJob est une synchronisation planifiable : son nom, son expression cron par défaut
+// et la fonction qui l'exécute.
+type syncJob struct {
+	name string
+	spec string
+	run  func()
+}
+
+// syncJobs liste toutes les synchronisations planifiées. Le nom sert de clé dans
+// syncConfig.Schedules et dans la variable SCHEDULE_<NOM> (voir scheduler.EnvVar).
+var syncJobs = []syncJob{
+	{"genres", "0 0 * * 0", SyncGenres},
+	{"configuration", "0 0 * * 0", SyncConfiguration},
+	{"movies", "0 * * * *", SyncMovies},
+	{"tv-shows", "0 * * * *", SyncTvShows},
+	// Décalé par rapport aux synchros horaires pour ne pas cumuler les appels TMDB
+	{"changes", "15 */6 * * *", SyncChanges},
+	{"film-recommendations", "0 0 * * *", SyncFilmsRecommendation},
+	{"tv-show-recommendations", "0 0 * * *", SyncTvShowsRecommendation},
+	{"credits", "30 2 * * *", SyncCredits},
+	{"people-details", "0 4 * * *", SyncPeopleDetails},
+	{"videos", "0 5 * * *", SyncVideos},
+	// La disponibilité change souvent : tout le catalogue toutes les six heures
+	{"watch-providers", "20 */6 * * *", SyncWatchProviders},
+	{"watch-provider-list", "10 3 * * *", SyncWatchProviderList},
+	{"certifications", "45 4 * * *", SyncCertifications},
+	{"certification-list", "30 0 * * 0", SyncCertificationList},
+	{"keywords", "15 5 * * *", SyncKeywords},
+	{"film-collections", "0 6 * * 0", SyncFilmCollections},
+	{"title-lists", "0 7 * * *", SyncTitleLists},
+}
+
+// jobScheduler est le scheduler où les jobs ont été enregistrés (voir RegisterJobs).
+var jobScheduler *scheduler.Scheduler
+
+// RegisterJobs enregistre toutes les synchronisations dans s, avec l'expression cron de
+// syncConfig.Schedules quand elle est renseignée. Un nom inconnu dans Schedules est une erreur.
+// Chaque exécution prend d'abord le verrou partagé du job (voir newLocker), pour qu'une
+// seule instance du serveur synchronise à la fois ; avec MULTI_INSTANCE=true, verrou et
+// checkpoints doivent être partagés entre machines (voir checkSharedBackends).
+func RegisterJobs(s *scheduler.Scheduler) error {
+	known := map[string]bool{}
+	for _, job := range syncJobs {
+		known[job.name] = true
+	}
+	var unknown []string
+	for name := range syncConfig.Schedules {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("schedules: job(s) inconnu(s): %s", strings.Join(unknown, ", "))
+	}
+
+	if err := checkSharedBackends(); err != nil {
+		return err
+	}
+	locker, ttl, err := newLocker()
+	if err != nil {
+		return fmt.Errorf("verrou des jobs: %w", err)
+	}
+	s.UseLocker(locker, ttl, instanceName())
+
+	for _, job := range syncJobs {
+		spec := job.spec
+		if override, ok := syncConfig.Schedules[job.name]; ok {
+			spec = override
+		}
+		if err := s.Register(job.name, spec, job.run); err != nil {
+			return err
+		}
+	}
+	jobScheduler = s
+	return nil
+}
+
+// JobsHandler liste les jobs enregistrés avec leur planification et leur prochaine exécution.
+func JobsHandler(w http.ResponseWriter, r *http.Request) {
+	if jobScheduler == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "aucun job enregistré")
+		return
+	}
+	writeJSON(w, http.StatusOK, jobScheduler.Jobs())
+}
+
+// triggerJob lance le job name pour une route de déclenchement et répond started suivi de
+// l'identifiant d'exécution. Si le job tourne déjà (cron ou appel précédent), rien n'est
+// lancé et la réponse est 409 avec l'identifiant de l'exécution en cours.
+func triggerJob(w http.ResponseWriter, name, started string) {
+	if jobScheduler == nil {
+		http.Error(w, "Scheduler non démarré", http.StatusServiceUnavailable)
+		return
+	}
+	run, err := jobScheduler.Trigger(name, scheduler.TriggerManual)
+	var running *scheduler.AlreadyRunningError
+	switch {
+	case errors.As(err, &running):
+		current := running.Current
+		w.WriteHeader(http.StatusConflict)
+		if current.ID == "" {
+			fmt.Fprintf(w, "Synchronisation %s déjà en cours sur une autre instance", name)
+			return
+		}
+		fmt.Fprintf(w, "Synchronisation %s déjà en cours (run %s sur %s, démarrée le %s)",
+			name, current.ID, current.Instance, current.StartedAt.Format(time.RFC3339))
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "%s (run %s)", started, run.ID)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"mon-projet/internal/scheduler"
+)
+
+func TestTriggerJob(t *testing.T) {
+	prev := jobScheduler
+	defer func() { jobScheduler = prev }()
+
+	jobScheduler = nil
+	w := httptest.NewRecorder()
+	triggerJob(w, "movies", "Synchronisation des films déclenchée")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("sans scheduler : code %d, attendu 503", w.Code)
+	}
+
+	s := scheduler.New()
+	started, release := make(chan struct{}), make(chan struct{})
+	if err := s.Register("movies", scheduler.Disabled, func() {
+		close(started)
+		<-release
+	}); err != nil {
+		t.Fatal(err)
+	}
+	jobScheduler = s
+
+	// Deux déclenchements simultanés : un seul lance le job, l'autre reçoit son identifiant
+	recorders := []*httptest.ResponseRecorder{httptest.NewRecorder(), httptest.NewRecorder()}
+	var wg sync.WaitGroup
+	for _, w := range recorders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			triggerJob(w, "movies", "Synchronisation des films déclenchée")
+		}()
+	}
+	wg.Wait()
+	<-started
+
+	ok, conflict := recorders[0], recorders[1]
+	if ok.Code != http.StatusOK {
+		ok, conflict = conflict, ok
+	}
+	if ok.Code != http.StatusOK || conflict.Code != http.StatusConflict {
+		t.Fatalf("codes %d et %d, attendu 200 et 409", recorders[0].Code, recorders[1].Code)
+	}
+	m := regexp.MustCompile(`^Synchronisation des films déclenchée \(run (\S+)\)$`).FindStringSubmatch(ok.Body.String())
+	if m == nil {
+		t.Fatalf("réponse 200 inattendue : %q", ok.Body.String())
+	}
+	if body := conflict.Body.String(); !strings.Contains(body, "déjà en cours (run "+m[1]+" sur ") {
+		t.Errorf("réponse 409 = %q, attendu le run %s", body, m[1])
+	}
+
+	close(release)
+	s.Stop()
+
+	w = httptest.NewRecorder()
+	triggerJob(w, "inconnu", "")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("job inconnu : code %d, attendu 500", w.Code)
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"mon-projet/internal/config"
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// titleTranslation est le titre et le résumé d'un film ou d'une série dans une locale
+type titleTranslation struct {
+	Title    string
+	Overview string
+}
+
+// locales renvoie les locales à ingérer ; la première est la locale principale
+func locales() []string {
+	if len(syncConfig.Locales) == 0 {
+		return []string{tmdbClient.Language}
+	}
+	return syncConfig.Locales
+}
+
+// secondaryLocales renvoie les locales autres que la locale principale
+func secondaryLocales() []string {
+	return locales()[1:]
+}
+
+// localizedCollections sont les collections qui reçoivent des localisations Strapi en mode "i18n"
+// (voir writeLocalizations)
+var localizedCollections = map[string]bool{
+	strapiFilms:           true,
+	strapiTvShows:         true,
+	strapiFilmCollections: true,
+	strapiGenres:          true,
+}
+
+// documents renvoie la collection name. En mode "i18n", une collection localisée est lue et
+// écrite dans la locale principale, envoyée explicitement plutôt que laissée à la locale par
+// défaut de Strapi, qui peut être différente.
+func documents(name string) *strapi.Collection[strapi.Document] {
+	col := collection[strapi.Document](name)
+	if syncConfig.LocaleMode == config.LocaleModeI18n && localizedCollections[name] {
+		return col.Localized(locales()[0])
+	}
+	return col
+}
+
+// localeSuffix renvoie le suffixe des champs d'une locale en mode "fields" (en-US → en_us)
+func localeSuffix(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "-", "_"))
+}
+
+// fetchTitleTranslations renvoie le titre et le résumé du titre tmdbID pour chaque locale secondaire
+// (voir titleTranslations), lus sur /{mediaType}/{id}/translations.
+func fetchTitleTranslations(ctx context.Context, mediaType string, tmdbID int, originalTitle, overview string) (map[string]titleTranslation, error) {
+	if len(secondaryLocales()) == 0 {
+		return nil, nil
+	}
+	translations, err := tmdbClient.Translations(ctx, mediaType, tmdbID)
+	if err != nil {
+		return nil, err
+	}
+	return titleTranslations(translations, originalTitle, overview), nil
+}
+
+// titleTranslations renvoie le titre et le résumé de chaque locale secondaire tirés de translations.
+// Un titre traduit vide retombe sur originalTitle, un résumé vide sur celui de la locale principale.
+func titleTranslations(translations *tmdb.Translations, originalTitle, overview string) map[string]titleTranslation {
+	extra := secondaryLocales()
+	if len(extra) == 0 {
+		return nil
+	}
+	out := make(map[string]titleTranslation, len(extra))
+	for _, locale := range extra {
+		tr := titleTranslation{Title: originalTitle, Overview: overview}
+		if data := translations.Find(locale); data != nil {
+			if title := data.DisplayTitle(); title != "" {
+				tr.Title = title
+			}
+			if data.Overview != "" {
+				tr.Overview = data.Overview
+			}
+		}
+		out[locale] = tr
+	}
+	return out
+}
+
+// missingLocaleFields indique si doc n'a pas encore les champs suffixés de chaque locale
+// secondaire (mode "fields"), par exemple parce que leur écriture a échoué
+func missingLocaleFields(doc strapi.Document, titleKey string) bool {
+	if syncConfig.LocaleMode != config.LocaleModeFields {
+		return false
+	}
+	for _, locale := range secondaryLocales() {
+		if isEmpty(doc[titleKey+"_"+localeSuffix(locale)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// upsertTitleTranslations enregistre les traductions d'un titre après son upsert (res, doc),
+// dans les champs suffixés (mode "fields") ou les localisations Strapi (mode "i18n").
+// Déjà connues (known, lues avec le détail via append_to_response), elles ont été comparées avec
+// le reste du payload ; sinon, elles ne sont demandées à TMDB que pour un titre nouveau ou
+// modifié, ou dont les traductions manquent. Renvoie le document à jour.
+func upsertTitleTranslations(ctx context.Context, name, titleKey string, res upsertResult, doc strapi.Document, known map[string]titleTranslation, fetch func() (map[string]titleTranslation, error)) (strapi.Document, error) {
+	translations := known
+	if translations == nil {
+		if res == upsertUnchanged && !missingLocaleFields(doc, titleKey) {
+			return doc, nil
+		}
+		var err error
+		if translations, err = fetch(); err != nil {
+			return doc, fmt.Errorf("traductions TMDB: %w", err)
+		}
+		fields := map[string]interface{}{}
+		addLocaleFields(fields, titleKey, translations)
+		if len(fields) > 0 {
+			_, updated, err := updateDocument(ctx, name, doc, fields)
+			if err != nil {
+				return doc, err
+			}
+			doc = updated
+		}
+	}
+	return doc, writeLocalizations(ctx, name, doc.DocumentID(), titleLocalizations(titleKey, translations))
+}
+
+// addLocaleFields ajoute au payload les champs suffixés de chaque locale (mode "fields")
+func addLocaleFields(payload map[string]interface{}, titleKey string, translations map[string]titleTranslation) {
+	if syncConfig.LocaleMode != config.LocaleModeFields {
+		return
+	}
+	for locale, tr := range translations {
+		suffix := localeSuffix(locale)
+		payload[titleKey+"_"+suffix] = tr.Title
+		payload["overview_"+suffix] = tr.Overview
+	}
+}
+
+// writeLocalizations crée ou met à jour la localisation Strapi de documentID pour chaque locale
+// (mode "i18n"). Seuls les champs qui ont changé sont envoyés.
+func writeLocalizations(ctx context.Context, name, documentID string, localized map[string]map[string]interface{}) error {
+	if syncConfig.LocaleMode != config.LocaleModeI18n || documentID == "" {
+		return nil
+	}
+	for locale, fields := range localized {
+		col := collection[strapi.Document](name).Localized(locale)
+
+		changes := fields
+		stored, err := col.FindOne(ctx, documentID, nil)
+		if err != nil && !strapi.IsNotFound(err) {
+			return fmt.Errorf("localisation %s: %w", locale, err)
+		}
+		if stored != nil {
+			changes = changedFields(*stored, fields)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if _, err := col.Update(ctx, documentID, changes); err != nil {
+			return fmt.Errorf("localisation %s: %w", locale, err)
+		}
+	}
+	return nil
+}
+
+// titleLocalizations convertit les traductions en champs localisés (mode "i18n")
+func titleLocalizations(titleKey string, translations map[string]titleTranslation) map[string]map[string]interface{} {
+	out := make(map[string]map[string]interface{}, len(translations))
+	for locale, tr := range translations {
+		out[locale] = map[string]interface{}{titleKey: tr.Title, "overview": tr.Overview}
+	}
+	return out
+}
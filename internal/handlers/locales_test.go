@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mon-projet/internal/config"
+	"mon-projet/internal/strapi"
+)
+
+// strapiRequest est une requête reçue par recordStrapi
+type strapiRequest struct {
+	Method, Path, Locale, Body string
+}
+
+// recordStrapi remplace Strapi par un serveur qui enregistre les requêtes reçues et renvoie
+// le corps data envoyé, avec la configuration cfg, le temps du test
+func recordStrapi(t *testing.T, cfg config.Config) *[]strapiRequest {
+	t.Helper()
+	var requests []strapiRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, strapiRequest{r.Method, r.URL.Path, r.URL.Query().Get("locale"), string(body)})
+		var in struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		json.Unmarshal(body, &in)
+		if in.Data == nil {
+			in.Data = map[string]interface{}{}
+		}
+		in.Data["documentId"] = "doc-1"
+		json.NewEncoder(w).Encode(in)
+	}))
+	t.Cleanup(srv.Close)
+
+	prevClient, prevConfig := strapiClient, syncConfig
+	t.Cleanup(func() { strapiClient, syncConfig = prevClient, prevConfig })
+	strapiClient = strapi.New(srv.URL, "")
+	syncConfig = &cfg
+	return &requests
+}
+
+func TestUpsertTitleTranslations(t *testing.T) {
+	translated := map[string]titleTranslation{"en-US": {Title: "Fight Club", Overview: "A ticking-time-bomb insomniac…"}}
+	complete := strapi.Document{"documentId": "doc-1", "title_en_us": "Fight Club", "overview_en_us": "A ticking-time-bomb insomniac…"}
+
+	tests := []struct {
+		name      string
+		mode      string
+		res       upsertResult
+		doc       strapi.Document
+		known     map[string]titleTranslation
+		wantFetch bool
+		wantPUTs  int
+	}{
+		{"titre inchangé : aucune lecture TMDB", config.LocaleModeFields, upsertUnchanged, complete, nil, false, 0},
+		{"titre inchangé sans traductions : lecture TMDB", config.LocaleModeFields, upsertUnchanged, strapi.Document{"documentId": "doc-1"}, nil, true, 1},
+		{"titre inséré : lecture TMDB", config.LocaleModeFields, upsertInserted, strapi.Document{"documentId": "doc-1"}, nil, true, 1},
+		{"titre modifié aux traductions déjà à jour", config.LocaleModeFields, upsertUpdated, complete, nil, true, 0},
+		{"traductions connues : déjà comparées avec le payload", config.LocaleModeFields, upsertUpdated, complete, translated, false, 0},
+		{"mode i18n, titre inchangé : aucune lecture TMDB", config.LocaleModeI18n, upsertUnchanged, complete, nil, false, 0},
+		{"mode i18n, titre inséré : localisation écrite", config.LocaleModeI18n, upsertInserted, strapi.Document{"documentId": "doc-1"}, nil, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := recordStrapi(t, config.Config{Locales: []string{"fr-FR", "en-US"}, LocaleMode: tt.mode})
+			fetched := false
+			_, err := upsertTitleTranslations(context.Background(), strapiFilms, "title", tt.res, tt.doc, tt.known, func() (map[string]titleTranslation, error) {
+				fetched = true
+				return translated, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fetched != tt.wantFetch {
+				t.Errorf("lecture TMDB = %v, attendu %v", fetched, tt.wantFetch)
+			}
+			puts := 0
+			for _, r := range *requests {
+				if r.Method == http.MethodPut {
+					puts++
+				}
+			}
+			if puts != tt.wantPUTs {
+				t.Errorf("%d écritures Strapi, attendu %d : %v", puts, tt.wantPUTs, *requests)
+			}
+		})
+	}
+}
+
+func TestDocumentsSendsMainLocale(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		collection string
+		wantLocale string
+	}{
+		{"mode i18n, collection localisée", config.LocaleModeI18n, strapiFilms, "fr-FR"},
+		{"mode i18n, collection non localisée", config.LocaleModeI18n, strapiPeople, ""},
+		{"mode fields", config.LocaleModeFields, strapiFilms, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := recordStrapi(t, config.Config{Locales: []string{"fr-FR", "en-US"}, LocaleMode: tt.mode})
+			if _, err := documents(tt.collection).Create(context.Background(), map[string]interface{}{"id_film": 550}); err != nil {
+				t.Fatal(err)
+			}
+			if got := (*requests)[0].Locale; got != tt.wantLocale {
+				t.Errorf("locale = %q, attendu %q", got, tt.wantLocale)
+			}
+		})
+	}
+}
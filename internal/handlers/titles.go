@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// storedTitle est un film ou une série déjà présent dans Strapi
+type storedTitle struct {
+	mediaType string
+	tmdbID    int
+	doc       strapi.Document
+}
+
+// titleRelationField renvoie le nom de la relation vers un titre de mediaType
+// dans les collections liées (crédits, vidéos…)
+func titleRelationField(mediaType string) string {
+	if mediaType == tmdb.MediaTV {
+		return "tv_show"
+	}
+	return "film"
+}
+
+// allPages lève la limite de pages d'un parcours : seul Enrichment.MaxRunTime borne le passage
+const allPages = -1
+
+// syncStoredTitles poursuit le parcours des titres stockés de mediaType pour job
+// (ex: "credits:movie") et appelle fn pour chacun d'eux (voir walkCollection).
+func syncStoredTitles(ctx context.Context, job, mediaType string, pagesPerRun int, fn func(context.Context, storedTitle) error) {
+	name, field := mediaCollection(mediaType)
+	walkCollection(ctx, job, name, pagesPerRun, func(ctx context.Context, doc strapi.Document) error {
+		id, err := strconv.Atoi(toString(doc[field]))
+		if err != nil {
+			return fmt.Errorf("%s invalide sur le document %s", field, doc.DocumentID())
+		}
+		if err := fn(ctx, storedTitle{mediaType: mediaType, tmdbID: id, doc: doc}); err != nil {
+			return fmt.Errorf("%s %d: %w", mediaType, id, err)
+		}
+		return nil
+	})
+}
+
+// walkCollection poursuit le parcours de la collection name pour job et appelle fn pour chaque
+// document, par lots (voir runBatched). Chaque passage retente d'abord une page en échec, puis
+// avance page par page (Enrichment.PageSize documents) jusqu'à épuiser son budget
+// (pagesPerRun pages, négatif : sans limite, et Enrichment.MaxRunTime) ou atteindre la fin de la collection :
+// le curseur repart alors de zéro au passage suivant. Les pages Strapi sont triées par id pour
+// que le curseur reste stable quand la collection grandit.
+func walkCollection(ctx context.Context, job, name string, pagesPerRun int, fn func(context.Context, strapi.Document) error) {
+	cp, err := loadCheckpoint(ctx, job, nil)
+	if err != nil {
+		log.Printf("❌ Lecture du checkpoint %s: %v", job, err)
+		return
+	}
+
+	budget := syncConfig.Enrichment
+	started := time.Now()
+	walked := 0
+	for pages := cp.Pending(); len(pages) > 0; {
+		if (pagesPerRun > 0 && walked >= pagesPerRun) || time.Since(started) >= time.Duration(budget.MaxRunTime) {
+			log.Printf("⏸️ %s : budget du passage atteint après %d pages, reprise page %d au prochain passage", job, walked, cp.Cursor+1)
+			break
+		}
+		if ctx.Err() != nil {
+			log.Printf("⚠️ %s : parcours interrompu: %v", job, ctx.Err())
+			break
+		}
+		page := pages[0]
+		pages = pages[1:]
+
+		list, err := collection[strapi.Document](name).Find(ctx, strapi.NewQuery().Sort("id:asc").Page(page, budget.PageSize))
+		if err != nil {
+			log.Printf("❌ %s : lecture de la page Strapi %d: %v", job, page, err)
+			break
+		}
+		if len(list.Data) == 0 {
+			recordPage(&cp, page, pageEmpty)
+			if page != cp.Cursor+1 {
+				continue
+			}
+			if cp.Cursor == 0 {
+				break // collection vide
+			}
+			// Fin de la collection : le parcours repart du début, dès ce passage s'il n'a rien traité
+			log.Printf("✅ %s : collection %s entièrement parcourue, reprise au début", job, name)
+			cp.Cursor = 0
+			if walked > 0 {
+				break
+			}
+			pages = []int{1}
+			continue
+		}
+
+		log.Printf("🔄 %s : page Strapi %d (%d documents)", job, page, len(list.Data))
+		failed := runBatched(ctx, list.Data, func(doc strapi.Document) error {
+			if err := fn(ctx, doc); err != nil {
+				return fmt.Errorf("%s: %w", job, err)
+			}
+			return nil
+		})
+		if failed == 0 {
+			cp.MarkDone(page, time.Now().UTC())
+		} else {
+			log.Printf("⚠️ %s : %d/%d documents en échec sur la page %d, on retentera plus tard", job, failed, len(list.Data), page)
+			cp.MarkFailed(page)
+		}
+		walked++
+		// Une page incomplète est la dernière : le passage suivant repart du début
+		if page == cp.Cursor && len(list.Data) < budget.PageSize {
+			log.Printf("✅ %s : collection %s entièrement parcourue, reprise au début", job, name)
+			cp.Cursor = 0
+			break
+		}
+		// Le checkpoint est enregistré à chaque page : un passage interrompu reprend où il s'est arrêté
+		saveCheckpoint(ctx, cp)
+		if len(pages) == 0 {
+			pages = []int{cp.Cursor + 1}
+		}
+	}
+	saveCheckpoint(ctx, cp)
+}
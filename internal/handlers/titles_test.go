@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"mon-projet/internal/checkpoint"
+	"mon-projet/internal/config"
+	"mon-projet/internal/strapi"
+)
+
+// fakeCatalogue remplace Strapi par une collection "films" de total documents (id 1..total)
+// et les checkpoints par un fichier temporaire, le temps du test.
+func fakeCatalogue(t *testing.T, total int, enrichment config.Enrichment) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("pagination[page]"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("pagination[pageSize]"))
+		data := []map[string]interface{}{}
+		for id := (page-1)*size + 1; id <= min(page*size, total); id++ {
+			data = append(data, map[string]interface{}{"id": id, "documentId": "doc-" + strconv.Itoa(id)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "meta": map[string]interface{}{}})
+	}))
+	t.Cleanup(srv.Close)
+
+	prevClient, prevStore, prevConfig := strapiClient, checkpoints, syncConfig
+	t.Cleanup(func() { strapiClient, checkpoints, syncConfig = prevClient, prevStore, prevConfig })
+	strapiClient = strapi.New(srv.URL, "")
+	checkpoints = checkpoint.NewFileStore(filepath.Join(t.TempDir(), "checkpoints.json"))
+	cfg := *prevConfig
+	cfg.Enrichment = enrichment
+	syncConfig = &cfg
+}
+
+func TestWalkCollectionBudget(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int
+		pagesPerRun int
+		// wantIDs sont les documents vus à chaque passage
+		wantIDs    [][]int
+		wantCursor int
+	}{
+		{
+			name:        "un passage s'arrête sur le nombre de pages",
+			total:       7,
+			pagesPerRun: 2,
+			wantIDs:     [][]int{{1, 2, 3, 4}, {5, 6, 7}, {1, 2, 3, 4}},
+			wantCursor:  2,
+		},
+		{
+			name:        "sans limite de pages, un passage parcourt toute la collection",
+			total:       5,
+			pagesPerRun: -1,
+			wantIDs:     [][]int{{1, 2, 3, 4, 5}, {1, 2, 3, 4, 5}},
+			wantCursor:  0,
+		},
+		{
+			name:        "une collection multiple de la page repart du début",
+			total:       4,
+			pagesPerRun: -1,
+			wantIDs:     [][]int{{1, 2, 3, 4}, {1, 2, 3, 4}},
+			wantCursor:  0,
+		},
+		{
+			name:        "un passage qui commence en fin de collection repart du début",
+			total:       4,
+			pagesPerRun: 2,
+			wantIDs:     [][]int{{1, 2, 3, 4}, {1, 2, 3, 4}},
+			wantCursor:  2,
+		},
+		{
+			name:        "collection vide",
+			total:       0,
+			pagesPerRun: -1,
+			wantIDs:     [][]int{nil},
+			wantCursor:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCatalogue(t, tt.total, config.Enrichment{
+				BatchSize: 10, PageSize: 2, MaxRunTime: config.Duration(time.Minute),
+			})
+			for run, want := range tt.wantIDs {
+				var mu sync.Mutex
+				var got []int
+				walkCollection(context.Background(), "test", "films", tt.pagesPerRun, func(ctx context.Context, doc strapi.Document) error {
+					id, _ := strconv.Atoi(doc.DocumentID()[len("doc-"):])
+					mu.Lock()
+					got = append(got, id)
+					mu.Unlock()
+					return nil
+				})
+				if !slices.Equal(got, want) {
+					t.Errorf("passage %d : documents %v, attendu %v", run+1, got, want)
+				}
+			}
+			cp, _, err := checkpoints.Load(context.Background(), "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cp.Cursor != tt.wantCursor {
+				t.Errorf("Cursor = %d, attendu %d", cp.Cursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestWalkCollectionRetriesFailedPageFirst(t *testing.T) {
+	fakeCatalogue(t, 6, config.Enrichment{
+		BatchSize: 10, PageSize: 2, MaxRunTime: config.Duration(time.Minute),
+	})
+	fail := true
+	var got []int
+	walk := func() {
+		got = nil
+		walkCollection(context.Background(), "test", "films", 1, func(ctx context.Context, doc strapi.Document) error {
+			id, _ := strconv.Atoi(doc.DocumentID()[len("doc-"):])
+			got = append(got, id)
+			if fail && id == 1 {
+				return context.DeadlineExceeded
+			}
+			return nil
+		})
+	}
+
+	walk()
+	fail = false
+	// La page 1 en échec est retentée avant la page 2, qui compte dans le budget
+	walk()
+	if want := []int{1, 2}; !slices.Equal(got, want) {
+		t.Errorf("retentative : documents %v, attendu %v", got, want)
+	}
+	walk()
+	if want := []int{3, 4}; !slices.Equal(got, want) {
+		t.Errorf("après la retentative : documents %v, attendu %v", got, want)
+	}
+}
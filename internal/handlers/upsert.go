@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+)
+
+// upsertResult indique ce qu'a fait upsertByTMDBID sur un document
+type upsertResult int
+
+const (
+	upsertInserted upsertResult = iota
+	upsertUpdated
+	upsertUnchanged
+)
+
+// insertOnlyFields ne sont écrits qu'à la création : la page d'origine sert de curseur
+// aux recommandations et ne doit pas bouger lors d'une mise à jour
+var insertOnlyFields = map[string]bool{
+	"page_fetched_from": true,
+}
+
+// relationSet est une relation Strapi (liste de documentId) envoyée sous la forme {"set": [...]},
+// qui remplace les liens existants
+type relationSet []string
+
+func (r relationSet) MarshalJSON() ([]byte, error) {
+	ids := []string(r)
+	if ids == nil {
+		ids = []string{}
+	}
+	return json.Marshal(map[string][]string{"set": ids})
+}
+
+// titleRelations porte les relations Strapi résolues d'un film ou d'une série.
+// Keywords est nil quand les mots-clés ne sont pas connus (ex: résultat discover) :
+// la relation n'est alors pas envoyée et les mots-clés stockés sont conservés.
+// Translations est renseigné quand le détail TMDB a été lu avec ses traductions
+// (append_to_response), pour ne pas les redemander (voir upsertTitleTranslations).
+type titleRelations struct {
+	Genres       relationSet
+	Keywords     relationSet
+	Translations *tmdb.Translations
+}
+
+// add ajoute au payload les relations optionnelles connues
+func (rel titleRelations) add(payload map[string]interface{}) {
+	if rel.Keywords != nil {
+		payload["keywords"] = rel.Keywords
+	}
+}
+
+// SyncStats compte le résultat d'une synchronisation (par page TMDB)
+type SyncStats struct {
+	Inserted  int
+	Updated   int
+	Unchanged int
+	Failed    int
+}
+
+func (s *SyncStats) add(res upsertResult) {
+	switch res {
+	case upsertInserted:
+		s.Inserted++
+	case upsertUpdated:
+		s.Updated++
+	case upsertUnchanged:
+		s.Unchanged++
+	}
+}
+
+func (s SyncStats) String() string {
+	return fmt.Sprintf("%d insérés, %d mis à jour, %d inchangés, %d en échec", s.Inserted, s.Updated, s.Unchanged, s.Failed)
+}
+
+// findByTMDBID renvoie le document de la collection dont field vaut tmdbID, ou nil s'il n'existe pas.
+// Les relations listées dans populate sont peuplées pour pouvoir être comparées.
+func findByTMDBID(ctx context.Context, name, field string, tmdbID int, populate ...string) (strapi.Document, error) {
+	doc, err := documents(name).First(ctx, strapi.NewQuery().Eq(field, tmdbID).Populate(populate...))
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return *doc, nil
+}
+
+// keyedMutex est un verrou par clé ; une clé qui n'est plus tenue est oubliée
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	holders int
+}
+
+// lock prend le verrou de key et renvoie la fonction qui le libère
+func (k *keyedMutex) lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = map[string]*keyedLock{}
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.holders++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mu.Lock()
+		if l.holders--; l.holders == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// upsertLocks sérialise, dans ce processus, les upserts d'un même document (collection et
+// identifiant TMDB) : une synchronisation et un import lancé depuis la recherche, qui ne passe
+// pas par le scheduler, ne peuvent pas tous deux ne pas trouver un titre puis le créer.
+var upsertLocks keyedMutex
+
+// upsertByTMDBID crée le document s'il n'existe pas, sinon n'envoie (PUT) que les champs
+// TMDB qui ont changé. Les champs *_website appartiennent à notre application et ne sont
+// jamais modifiés après l'insertion, pas plus que insertOnlyFields. En mode "i18n", les
+// collections localisées sont écrites dans la locale principale (voir documents).
+// Les upserts d'un même document sont sérialisés (voir upsertLocks) ; si une autre instance
+// l'a créé entre-temps (field est unique dans Strapi), il est mis à jour.
+// Le document renvoyé est celui créé, mis à jour ou déjà stocké.
+func upsertByTMDBID(ctx context.Context, name, field string, tmdbID int, payload map[string]interface{}) (upsertResult, strapi.Document, error) {
+	unlock := upsertLocks.lock(name + ":" + strconv.Itoa(tmdbID))
+	defer unlock()
+
+	stored, err := findByTMDBID(ctx, name, field, tmdbID, relationKeys(payload)...)
+	if err != nil {
+		return 0, nil, err
+	}
+	if stored == nil {
+		created, createErr := documents(name).Create(ctx, payload)
+		if createErr == nil {
+			return upsertInserted, derefDocument(created), nil
+		}
+		if !strapi.IsUniqueViolation(createErr, field) {
+			return 0, nil, createErr
+		}
+		if stored, err = findByTMDBID(ctx, name, field, tmdbID, relationKeys(payload)...); err != nil {
+			return 0, nil, err
+		}
+		if stored == nil {
+			return 0, nil, createErr
+		}
+	}
+	return updateDocument(ctx, name, stored, payload, field)
+}
+
+// detailsSyncedAt est l'horodatage du dernier enrichissement d'un document (voir updateDocument)
+const detailsSyncedAt = "details_synced_at"
+
+// updateDocument met à jour un document déjà lu avec les seuls champs modifiés de payload.
+// details_synced_at, toujours nouveau, n'est envoyé que si un autre champ a changé ou s'il
+// n'a jamais été écrit : un enrichissement sans changement ne déclenche pas d'écriture.
+func updateDocument(ctx context.Context, name string, stored strapi.Document, payload map[string]interface{}, skip ...string) (upsertResult, strapi.Document, error) {
+	changes := changedFields(stored, payload, append(slices.Clone(skip), detailsSyncedAt)...)
+	if syncedAt, ok := payload[detailsSyncedAt]; ok && (len(changes) > 0 || isEmpty(stored[detailsSyncedAt])) {
+		changes[detailsSyncedAt] = syncedAt
+	}
+	if len(changes) == 0 {
+		return upsertUnchanged, stored, nil
+	}
+	updated, err := documents(name).Update(ctx, stored.DocumentID(), changes)
+	if err != nil {
+		return 0, nil, err
+	}
+	return upsertUpdated, derefDocument(updated), nil
+}
+
+func derefDocument(doc *strapi.Document) strapi.Document {
+	if doc == nil {
+		return strapi.Document{}
+	}
+	return *doc
+}
+
+// mediaCollection renvoie la collection Strapi et le champ d'identifiant TMDB d'un type de média
+func mediaCollection(mediaType string) (name, field string) {
+	if mediaType == tmdb.MediaTV {
+		return strapiTvShows, "id_TvShow"
+	}
+	return strapiFilms, "id_film"
+}
+
+// findManyBatchSize borne le nombre d'identifiants par filtre $in
+const findManyBatchSize = 100
+
+// findManyByTMDBID renvoie, pour les identifiants TMDB présents dans la collection,
+// le document stocké correspondant. Les lectures sont groupées par lots de findManyBatchSize.
+// fields restreint les attributs lus : field est toujours lu, documentId est toujours renvoyé.
+func findManyByTMDBID(ctx context.Context, name, field string, ids []int, fields ...string) (map[int]strapi.Document, error) {
+	found := make(map[int]strapi.Document, len(ids))
+	for start := 0; start < len(ids); start += findManyBatchSize {
+		end := min(start+findManyBatchSize, len(ids))
+		values := make([]interface{}, 0, end-start)
+		for _, id := range ids[start:end] {
+			values = append(values, id)
+		}
+
+		q := strapi.NewQuery().In(field, values...)
+		if len(fields) > 0 {
+			q.Fields(field)
+			for _, f := range fields {
+				if f != field {
+					q.Fields(f)
+				}
+			}
+		}
+		docs, err := collection[strapi.Document](name).All(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			id, err := strconv.Atoi(toString(doc[field]))
+			if err != nil {
+				continue
+			}
+			found[id] = doc
+		}
+	}
+	return found, nil
+}
+
+// changedFields renvoie les champs de fresh dont la valeur diffère de stored,
+// en ignorant les champs *_website, insertOnlyFields et les clés listées dans skip
+func changedFields(stored strapi.Document, fresh map[string]interface{}, skip ...string) map[string]interface{} {
+	changes := map[string]interface{}{}
+	for key, value := range fresh {
+		if strings.HasSuffix(key, "_website") || insertOnlyFields[key] || slices.Contains(skip, key) {
+			continue
+		}
+		if !sameValue(stored[key], value) {
+			changes[key] = value
+		}
+	}
+	return changes
+}
+
+// relationKeys renvoie les champs du payload qui sont des relations
+func relationKeys(payload map[string]interface{}) []string {
+	var keys []string
+	for key, value := range payload {
+		if _, ok := value.(relationSet); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// sameRelation compare une relation peuplée par Strapi ([{documentId…}] en v5,
+// {"data": [{id, attributes}]} en v4) à une relationSet
+func sameRelation(stored interface{}, fresh relationSet) bool {
+	if v4, ok := stored.(map[string]interface{}); ok {
+		stored = v4["data"]
+	}
+	items, _ := stored.([]interface{})
+	if len(items) != len(fresh) {
+		return false
+	}
+	want := map[string]bool{}
+	for _, id := range fresh {
+		want[id] = true
+	}
+	for _, item := range items {
+		doc, ok := item.(map[string]interface{})
+		if !ok || !want[strapi.Document(doc).DocumentID()] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameValue compare une valeur décodée depuis Strapi à une valeur Go du payload.
+// Strapi renvoie les biginteger/decimal en chaîne et les champs vides en null.
+func sameValue(stored, fresh interface{}) bool {
+	if rel, ok := fresh.(relationSet); ok {
+		return sameRelation(stored, rel)
+	}
+	fresh = normalizeJSON(fresh)
+	if isEmpty(stored) && isEmpty(fresh) {
+		return true
+	}
+	switch f := fresh.(type) {
+	case float64:
+		switch s := stored.(type) {
+		case float64:
+			return s == f
+		case string:
+			n, err := strconv.ParseFloat(s, 64)
+			return err == nil && n == f
+		}
+		return false
+	case string:
+		if s, ok := stored.(float64); ok {
+			n, err := strconv.ParseFloat(f, 64)
+			return err == nil && n == s
+		}
+	case []interface{}:
+		s, ok := stored.([]interface{})
+		if !ok || len(s) != len(f) {
+			return false
+		}
+		for i := range f {
+			if !sameValue(s[i], f[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(stored, fresh)
+}
+
+// normalizeJSON ramène une valeur Go aux types produits par encoding/json (float64, []interface{}…)
+func normalizeJSON(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func isEmpty(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case []interface{}:
+		return len(t) == 0
+	}
+	return false
+}
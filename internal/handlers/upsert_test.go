@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"mon-projet/internal/strapi"
+)
+
+// decodeStored décode un document tel que Strapi le renvoie
+func decodeStored(t *testing.T, raw string) strapi.Document {
+	t.Helper()
+	var doc strapi.Document
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestSameValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		fresh  interface{}
+		want   bool
+	}{
+		{"entier identique", `123`, 123, true},
+		{"entier différent", `123`, 124, false},
+		{"biginteger stocké en chaîne", `"9007199254740993"`, int64(9007199254740993), true},
+		{"decimal stocké en chaîne", `"7.50"`, 7.5, true},
+		{"decimal stocké en chaîne différent", `"7.50"`, 7.6, false},
+		{"chaîne numérique face à un nombre stocké", `8.1`, "8.1", true},
+		{"chaîne non numérique face à un nombre stocké", `8.1`, "abc", false},
+		{"null face à une chaîne vide", `null`, "", true},
+		{"null face à une liste vide", `null`, []string{}, true},
+		{"null face à nil", `null`, nil, true},
+		{"null face à une valeur", `null`, "Fight Club", false},
+		{"null face à zéro", `null`, 0, false},
+		{"null face à false", `null`, false, false},
+		{"chaîne vide face à une valeur", `""`, "Fight Club", false},
+		{"booléen identique", `true`, true, true},
+		{"liste de nombres stockés en chaîne", `["1", "2"]`, []int{1, 2}, true},
+		{"liste d'ordre différent", `[1, 2]`, []int{2, 1}, false},
+		{"liste de longueur différente", `[1, 2]`, []int{1}, false},
+		{"objet JSON identique", `{"iso_3166_1": "FR", "rating": 12}`, map[string]interface{}{"iso_3166_1": "FR", "rating": 12}, true},
+		{"relation identique dans un autre ordre", `[{"documentId": "a"}, {"documentId": "b"}]`, relationSet{"b", "a"}, true},
+		{"relation différente", `[{"documentId": "a"}, {"documentId": "b"}]`, relationSet{"a", "c"}, false},
+		{"relation avec un lien en plus", `[{"documentId": "a"}]`, relationSet{"a", "b"}, false},
+		{"relation vide face à null", `null`, relationSet{}, true},
+		{"relation vide face à une liste vide", `[]`, relationSet{}, true},
+		{"relation v4 par id numérique", `[{"id": 3}, {"id": 12}]`, relationSet{"12", "3"}, true},
+		{"relation v4 enveloppée dans data", `{"data": [{"id": 3, "attributes": {"name": "Drame"}}]}`, relationSet{"3"}, true},
+		{"relation v4 vide enveloppée dans data", `{"data": []}`, relationSet{}, true},
+		{"relation v4 différente enveloppée dans data", `{"data": [{"id": 3, "attributes": {}}]}`, relationSet{"4"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored interface{}
+			if err := json.Unmarshal([]byte(tt.stored), &stored); err != nil {
+				t.Fatal(err)
+			}
+			if got := sameValue(stored, tt.fresh); got != tt.want {
+				t.Errorf("sameValue(%s, %#v) = %v, attendu %v", tt.stored, tt.fresh, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	stored := decodeStored(t, `{
+		"documentId": "abc",
+		"id_film": "550",
+		"title": "Fight Club",
+		"vote_average": "8.4",
+		"overview": null,
+		"note_website": 5,
+		"page_fetched_from": 3,
+		"genres": [{"documentId": "g1"}],
+		"details_synced_at": "2025-01-01T00:00:00Z"
+	}`)
+
+	tests := []struct {
+		name  string
+		fresh map[string]interface{}
+		skip  []string
+		want  []string
+	}{
+		{
+			name:  "rien n'a changé",
+			fresh: map[string]interface{}{"id_film": 550, "title": "Fight Club", "vote_average": 8.4, "overview": "", "genres": relationSet{"g1"}},
+		},
+		{
+			name:  "seuls les champs modifiés sont renvoyés",
+			fresh: map[string]interface{}{"title": "Fight Club", "vote_average": 8.5, "overview": "Un employé de bureau…"},
+			want:  []string{"overview", "vote_average"},
+		},
+		{
+			name:  "les champs *_website ne sont jamais écrasés",
+			fresh: map[string]interface{}{"note_website": 0, "like_website": 1},
+		},
+		{
+			name:  "page_fetched_from n'est écrit qu'à l'insertion",
+			fresh: map[string]interface{}{"page_fetched_from": 7},
+		},
+		{
+			name:  "les clés de skip sont ignorées",
+			fresh: map[string]interface{}{"id_film": 551, "details_synced_at": "2025-02-01T00:00:00Z"},
+			skip:  []string{"id_film", detailsSyncedAt},
+		},
+		{
+			name:  "une relation modifiée est renvoyée",
+			fresh: map[string]interface{}{"genres": relationSet{"g1", "g2"}},
+			want:  []string{"genres"},
+		},
+		{
+			name:  "un champ absent du document stocké est renvoyé s'il a une valeur",
+			fresh: map[string]interface{}{"tagline": "Mischief. Mayhem. Soap.", "homepage": ""},
+			want:  []string{"tagline"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := changedFields(stored, tt.fresh, tt.skip...)
+			got := slices.Sorted(maps.Keys(changes))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("changedFields = %v, attendu %v", got, tt.want)
+			}
+			for key, value := range changes {
+				if !reflectSame(value, tt.fresh[key]) {
+					t.Errorf("changedFields[%q] = %#v, attendu la valeur du payload %#v", key, value, tt.fresh[key])
+				}
+			}
+		})
+	}
+}
+
+// reflectSame compare deux valeurs du payload par leur encodage JSON
+func reflectSame(a, b interface{}) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   interface{}
+		want string
+	}{
+		{"entier", 42, `42`},
+		{"liste d'entiers", []int{1, 2}, `[1,2]`},
+		{"liste nil", []string(nil), `null`},
+		{"struct", struct {
+			Name string `json:"name"`
+		}{"Drame"}, `{"name":"Drame"}`},
+		{"relation", relationSet{"a"}, `{"set":["a"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want interface{}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if got := normalizeJSON(tt.in); !reflectSame(got, want) {
+				t.Errorf("normalizeJSON(%#v) = %#v, attendu %s", tt.in, got, tt.want)
+			}
+		})
+	}
+	// Une valeur non encodable est renvoyée telle quelle
+	ch := make(chan int)
+	if got := normalizeJSON(ch); got != interface{}(ch) {
+		t.Errorf("normalizeJSON(chan) = %#v", got)
+	}
+}
+
+func TestUpsertByTMDBIDSerializesSameTitle(t *testing.T) {
+	var mu sync.Mutex
+	stored := map[string]bool{}
+	posts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id := r.URL.Query().Get("filters[id_film][$eq]")
+		switch r.Method {
+		case http.MethodGet:
+			data := []map[string]interface{}{}
+			if stored[id] {
+				data = append(data, map[string]interface{}{"documentId": "doc-" + id, "id_film": id, "title": "Fight Club"})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		case http.MethodPost:
+			var in struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			json.NewDecoder(r.Body).Decode(&in)
+			id := fmt.Sprint(in.Data["id_film"])
+			// Laisse aux autres upserts le temps de lire avant que le document n'existe
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			posts++
+			stored[id] = true
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"documentId": "doc-" + id}})
+		}
+	}))
+	defer srv.Close()
+	prevClient := strapiClient
+	defer func() { strapiClient = prevClient }()
+	strapiClient = strapi.New(srv.URL, "")
+
+	var wg sync.WaitGroup
+	results := make([]upsertResult, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, _, err := upsertByTMDBID(context.Background(), strapiFilms, "id_film", 550, map[string]interface{}{"id_film": 550, "title": "Fight Club"})
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+
+	if posts != 1 {
+		t.Errorf("%d créations pour un même titre, attendu 1", posts)
+	}
+	inserted := 0
+	for _, res := range results {
+		if res == upsertInserted {
+			inserted++
+		}
+	}
+	if inserted != 1 {
+		t.Errorf("%d upserts ont inséré le titre, attendu 1 : %v", inserted, results)
+	}
+}
+
+func TestUpsertByTMDBIDUpdatesOnUniqueViolation(t *testing.T) {
+	gets, puts := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			gets++
+			data := []map[string]interface{}{}
+			// Le document est créé par une autre instance entre la lecture et la création
+			if gets > 1 {
+				data = append(data, map[string]interface{}{"documentId": "doc-550", "id_film": 550, "title": "Fight Club"})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		case http.MethodPost:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"data": null, "error": {"status": 400, "name": "ValidationError", "message": "This attribute must be unique",
+				"details": {"errors": [{"path": ["id_film"], "message": "This attribute must be unique", "name": "ValidationError"}]}}}`))
+		case http.MethodPut:
+			puts++
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"documentId": "doc-550"}})
+		}
+	}))
+	defer srv.Close()
+	prevClient := strapiClient
+	defer func() { strapiClient = prevClient }()
+	strapiClient = strapi.New(srv.URL, "")
+
+	res, doc, err := upsertByTMDBID(context.Background(), strapiFilms, "id_film", 550, map[string]interface{}{"id_film": 550, "title": "Fight Club (1999)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != upsertUpdated || doc.DocumentID() != "doc-550" || puts != 1 {
+		t.Errorf("upsert = %v %v, %d mises à jour ; attendu une mise à jour de doc-550", res, doc, puts)
+	}
+}
@@ -1,219 +1,293 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"strconv"
+	"time"
+
+	"mon-projet/internal/checkpoint"
+	"mon-projet/internal/config"
+	"mon-projet/internal/lock"
+	"mon-projet/internal/strapi"
+	"mon-projet/internal/tmdb"
+
+	"github.com/joho/godotenv"
 )
 
 var (
-	strapiToken string
+	// tmdbClient est partagé par toutes les synchronisations (voir tmdb.NewFromEnv)
+	tmdbClient *tmdb.Client
+	// strapiClient est partagé par toutes les synchronisations (voir strapi.NewFromEnv)
+	strapiClient *strapi.Client
+	// checkpoints conserve l'avancement de chaque job (voir newCheckpointStore)
+	checkpoints checkpoint.Store
+	// syncConfig est la configuration des jobs (voir config.LoadFromEnv)
+	syncConfig *config.Config
 )
 
 func init() {
-	strapiToken = os.Getenv("STRAPI_TOKEN")
-}
-
-func Exists(tmdbID int, endpoint string) (bool, error) {
-	url := fmt.Sprintf("%s=%d", endpoint, tmdbID)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+strapiToken)
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-      log.Printf("⚠️ Erreur lors de la vérification d'existence du film %d : %v", tmdbID, err)
-		return false, err
+	if err := godotenv.Load(); err != nil {
+		log.Printf("⚠️ .env non chargé: %v", err)
 	}
-	defer res.Body.Close()
+	tmdbClient = tmdb.NewFromEnv()
+	strapiClient = strapi.NewFromEnv()
+	checkpoints = newCheckpointStore()
 
-	var data struct {
-		Data []interface{} `json:"data"`
+	var err error
+	if syncConfig, err = config.LoadFromEnv(); err != nil {
+		log.Fatalf("Erreur configuration des synchronisations: %v", err)
 	}
-	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
-		log.Printf("⚠️ Erreur lors du décodage de la réponse d'existence : %v", err)
-		return false, err
+	// La locale principale sert de langue par défaut à tous les appels TMDB
+	if len(syncConfig.Locales) > 0 {
+		tmdbClient.Language = syncConfig.Locales[0]
 	}
-	return len(data.Data) > 0, nil
 }
 
+// minLockTTL est la plus courte durée de verrou acceptée : le verrou est prolongé tous les
+// tiers de TTL, et une prise Strapi est relue deux secondes après son écriture
+const minLockTTL = 30 * time.Second
 
-// getLastFetchedPage interroge Strapi pour la plus grande page_fetched_from existante
-// la fonction getLastFetchedPage renvoie la dernière page ou le serveur a arrêté de récupérer les films lors de dernier appel
-// Alors l'idée ici est que j'ai ajouté pour chaque film un attribut page_fetched_from qui est incrémenté à chaque fois que je fais une requête vers TMDB
-// et que je l'enregistre dans Strapi. Donc si je fais une requête vers TMDB et que je récupère 20 films, je vais incrémenter page_fetched_from de 1
-// Franchement jai eu cette idée dans le mitro lorsque une veille femme qu'été à coté de moi  a mets un petit papier dans son livre lorsque elle a terminée de lire
-// pourqu'elle puisse savoir dans la prochaine lecture où elle s'est arrêté de lire que je me suis inspiré de l'idée page_fetched_from
+// lockBackend renvoie LOCK_BACKEND, "file" par défaut.
+func lockBackend() string {
+	if backend := os.Getenv("LOCK_BACKEND"); backend != "" {
+		return backend
+	}
+	return "file"
+}
 
-func getLastFetchedPage(tmdbUrl string) int {
+// sharedLock indique si le verrou des jobs est partagé entre machines (strapi ou postgres)
+func sharedLock() bool {
+	return lockBackend() == "strapi" || lockBackend() == "postgres"
+}
 
-	url := fmt.Sprintf("%s?sort=page_fetched_from:desc&pagination[limit]=1", tmdbUrl)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		log.Printf("⚠️ Erreur création requête pagination: %v", err)
-		return 0
+// checkpointBackend renvoie CHECKPOINT_BACKEND. Par défaut, les checkpoints suivent le verrou :
+// "strapi" avec un verrou partagé entre machines, sinon "file".
+func checkpointBackend() string {
+	if backend := os.Getenv("CHECKPOINT_BACKEND"); backend != "" {
+		return backend
 	}
-	req.Header.Set("Authorization", "Bearer "+strapiToken)
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Printf("⚠️ Erreur requête pagination: %v", err)
-		return 0
+	if sharedLock() {
+		return "strapi"
 	}
-	defer res.Body.Close()
+	return "file"
+}
 
-	var resp struct {
-		Data []struct {
-			PageFetchedFrom string `json:"page_fetched_from"`
-		} `json:"data"`
+// checkSharedBackends vérifie qu'avec MULTI_INSTANCE=true (plusieurs instances sur des machines
+// différentes), le verrou des jobs et les checkpoints sont partagés : avec un verrou ou un
+// fichier de checkpoints local, chaque instance synchroniserait de son côté.
+func checkSharedBackends() error {
+	if multi, _ := strconv.ParseBool(os.Getenv("MULTI_INSTANCE")); !multi {
+		return nil
 	}
-
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		log.Printf("⚠️ Décodage pagination Strapi: %v", err)
-		return 0
+	if !sharedLock() {
+		return fmt.Errorf("MULTI_INSTANCE=true demande LOCK_BACKEND=strapi ou postgres (actuellement %s)", lockBackend())
 	}
-
-	if len(resp.Data) == 0 {
-		log.Printf("📦 Aucune donnée dans la réponse")
-		return 0
+	if checkpointBackend() != "strapi" {
+		return fmt.Errorf("MULTI_INSTANCE=true demande CHECKPOINT_BACKEND=strapi (actuellement %s)", checkpointBackend())
 	}
+	return nil
+}
 
-	// Convertir la chaîne en int
-	page, err := strconv.Atoi(resp.Data[0].PageFetchedFrom)
-	if err != nil {
-	log.Printf("⚠️ Échec de la conversion de page_fetched_from en entier : %v", err)
-		return 0
+// newCheckpointStore choisit le backend des checkpoints (voir checkpointBackend) :
+// "strapi" (collection sync-checkpoints) ou "file" (CHECKPOINT_FILE, par défaut sync_checkpoints.json)
+func newCheckpointStore() checkpoint.Store {
+	if checkpointBackend() == "strapi" {
+		return checkpoint.NewStrapiStore(strapiClient, "sync-checkpoints")
 	}
-
-	log.Printf("📦 Dernière page récupérée: %d", page)
-	return page
-
+	path := os.Getenv("CHECKPOINT_FILE")
+	if path == "" {
+		path = "sync_checkpoints.json"
+	}
+	return checkpoint.NewFileStore(path)
 }
 
-func getLastFetchedPageFilmStrapi(url string) int {
-	url = fmt.Sprintf("%s?sort=page_fetched_from_strapi_film:desc&pagination[limit]=1", url)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		log.Printf("⚠️ Erreur création requête pagination: %v", err)
-		return 0
+// newLocker choisit le verrou partagé des jobs selon LOCK_BACKEND : "strapi" (collection
+// sync-locks), "postgres" (verrou consultatif sur DATABASE_URL) ou "file" (LOCK_DIR,
+// par défaut .locks), ainsi que sa durée LOCK_TTL (10m par défaut, au moins minLockTTL).
+func newLocker() (lock.Locker, time.Duration, error) {
+	ttl := 10 * time.Minute
+	if v := os.Getenv("LOCK_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < minLockTTL {
+			return nil, 0, fmt.Errorf("LOCK_TTL %q invalide (durée d'au moins %s, ex: \"10m\")", v, minLockTTL)
+		}
+		ttl = d
+	}
+	switch backend := lockBackend(); backend {
+	case "strapi":
+		return lock.NewStrapiLocker(strapiClient, "sync-locks"), ttl, nil
+	case "postgres":
+		dsn := os.Getenv("DATABASE_URL")
+		if dsn == "" {
+			return nil, 0, fmt.Errorf("LOCK_BACKEND=postgres sans DATABASE_URL")
+		}
+		locker, err := lock.NewPostgresLocker(dsn)
+		return locker, ttl, err
+	case "file":
+		dir := os.Getenv("LOCK_DIR")
+		if dir == "" {
+			dir = ".locks"
+		}
+		return lock.NewFileLocker(dir), ttl, nil
+	default:
+		return nil, 0, fmt.Errorf("LOCK_BACKEND %q inconnu (attendu file, strapi ou postgres)", backend)
 	}
-	req.Header.Set("Authorization", "Bearer "+strapiToken)
+}
 
-	res, err := http.DefaultClient.Do(req)
+// instanceName identifie ce serveur auprès des autres instances (RENDER_INSTANCE_ID sur
+// Render, sinon le nom de la machine et le pid).
+func instanceName() string {
+	if id := os.Getenv("RENDER_INSTANCE_ID"); id != "" {
+		return id
+	}
+	host, err := os.Hostname()
 	if err != nil {
-		log.Printf("⚠️ Erreur requête pagination: %v", err)
-		return 0
+		host = "localhost"
 	}
-	defer res.Body.Close()
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
 
-	var resp struct {
-		Data []struct {
-			PageFetchedFrom string `json:"page_fetched_from_strapi_film"`
-		} `json:"data"`
+// loadCheckpoint lit le checkpoint de job. S'il n'existe pas encore, le curseur est
+// initialisé avec legacy (l'ancienne reprise par page_fetched_from) pour ne pas repartir de zéro.
+func loadCheckpoint(ctx context.Context, job string, legacy func() int) (checkpoint.Checkpoint, error) {
+	cp, found, err := checkpoints.Load(ctx, job)
+	if err != nil {
+		return cp, err
 	}
-
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		log.Printf("⚠️ Décodage pagination Strapi: %v", err)
-		return 0
+	if !found && legacy != nil {
+		cp.Cursor = legacy()
+		log.Printf("📌 Checkpoint %s initialisé à la page %d", job, cp.Cursor)
 	}
+	return cp, nil
+}
 
-	if len(resp.Data) == 0 {
-		log.Printf("📦 Aucune donnée dans la réponse")
-		return 0
+// pipelineJob renvoie le nom du checkpoint d'un pipeline discover : le pipeline par défaut
+// garde le nom historique du job (ex: "movies"), les autres sont suffixés (ex: "movies:recent-fr")
+func pipelineJob(job string, p config.Pipeline) string {
+	if p.Name == config.DefaultPipeline {
+		return job
 	}
+	return job + ":" + p.Name
+}
 
-	// Convertir la chaîne en int
-	page, err := strconv.Atoi(resp.Data[0].PageFetchedFrom)
+// discoveredPages renvoie le curseur du checkpoint job (ex: "movies") : les pages discover
+// déjà parcourues. 0 si le checkpoint est illisible ou absent.
+func discoveredPages(ctx context.Context, job string) int {
+	cp, _, err := checkpoints.Load(ctx, job)
 	if err != nil {
-     log.Printf("⚠️ Échec de la conversion de « page_fetched_from » en entier : %v", err)
+		log.Printf("⚠️ Lecture du checkpoint %s: %v", job, err)
 		return 0
 	}
+	return cp.Cursor
+}
 
-	log.Printf("📦 Dernière page récupérée: %d", page)
-	return page
+// pageOutcome est le résultat du traitement d'une page, à reporter dans son checkpoint (voir recordPage).
+type pageOutcome int
+
+const (
+	// pageUnread : la page n'a pas pu être lue (erreur) ou pas encore ; on retentera.
+	pageUnread pageOutcome = iota
+	// pageEmpty : la source ne renvoie plus rien pour cette page.
+	pageEmpty
+	// pageDone : tous les éléments de la page ont réussi.
+	pageDone
+	// pageFailed : au moins un élément de la page a échoué.
+	pageFailed
+)
 
+// recordPage reporte outcome pour page dans cp. Une page vide est retirée des pages en échec
+// pour ne pas être retentée indéfiniment.
+func recordPage(cp *checkpoint.Checkpoint, page int, outcome pageOutcome) {
+	switch outcome {
+	case pageDone:
+		cp.MarkDone(page, time.Now().UTC())
+	case pageFailed:
+		cp.MarkFailed(page)
+	case pageEmpty:
+		if cp.Drop(page) {
+			log.Printf("🧹 %s : page %d en échec désormais vide, abandonnée", cp.Job, page)
+		}
+	}
 }
 
-func getLastFetchedPageTvShowsStrapi(url string) int {
-	url = fmt.Sprintf("%s?sort=page_fetched_from_strapi_TvShow:desc&pagination[limit]=1", url)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		log.Printf("⚠️ Erreur création requête pagination: %v", err)
-		return 0
+func saveCheckpoint(ctx context.Context, cp checkpoint.Checkpoint) {
+	if err := checkpoints.Save(ctx, cp); err != nil {
+		log.Printf("⚠️ Enregistrement du checkpoint %s impossible : %v", cp.Job, err)
 	}
-	req.Header.Set("Authorization", "Bearer "+strapiToken)
+}
+
+// collection renvoie le client Strapi de la collection name, décodée dans T
+func collection[T any](name string) *strapi.Collection[T] {
+	return strapi.NewCollection[T](strapiClient, name)
+}
 
-	res, err := http.DefaultClient.Do(req)
+// ExistsMany renvoie le documentId des identifiants TMDB tmdbIDs présents dans la collection
+// (champ field), en une lecture par lot ; les absents ne figurent pas dans la map
+func ExistsMany(ctx context.Context, name, field string, tmdbIDs []int) (map[int]string, error) {
+	// Seul l'identifiant TMDB est lu : documentId est toujours renvoyé par Strapi
+	docs, err := findManyByTMDBID(ctx, name, field, tmdbIDs, field)
 	if err != nil {
-		log.Printf("⚠️ Erreur requête pagination: %v", err)
-		return 0
+		log.Printf("⚠️ Erreur lors de la vérification d'existence dans %s : %v", name, err)
+		return nil, err
 	}
-	defer res.Body.Close()
-
-	var resp struct {
-		Data []struct {
-			PageFetchedFrom string `json:"page_fetched_from_strapi_TvShow"`
-		} `json:"data"`
+	found := make(map[int]string, len(docs))
+	for id, doc := range docs {
+		found[id] = doc.DocumentID()
 	}
+	return found, nil
+}
 
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		log.Printf("⚠️ Décodage pagination Strapi: %v", err)
+// getLastFetchedPage interroge Strapi pour la plus grande page_fetched_from existante
+// la fonction getLastFetchedPage renvoie la dernière page ou le serveur a arrêté de récupérer les films lors de dernier appel
+// Alors l'idée ici est que j'ai ajouté pour chaque film un attribut page_fetched_from qui est incrémenté à chaque fois que je fais une requête vers TMDB
+// et que je l'enregistre dans Strapi. Donc si je fais une requête vers TMDB et que je récupère 20 films, je vais incrémenter page_fetched_from de 1
+// Franchement jai eu cette idée dans le mitro lorsque une veille femme qu'été à coté de moi  a mets un petit papier dans son livre lorsque elle a terminée de lire
+// pourqu'elle puisse savoir dans la prochaine lecture où elle s'est arrêté de lire que je me suis inspiré de l'idée page_fetched_from
+//
+// Elle ne sert plus qu'à initialiser les checkpoints (voir loadCheckpoint).
+//
+// field est le champ de page à utiliser : page_fetched_from pour films et séries,
+// page_fetched_from_strapi_film / page_fetched_from_strapi_TvShow pour les recommandations.
+func getLastFetchedPage(ctx context.Context, name, field string) int {
+	doc, err := collection[strapi.Document](name).First(ctx, strapi.NewQuery().Sort(field+":desc").Fields(field))
+	if err != nil {
+		log.Printf("⚠️ Erreur requête pagination: %v", err)
 		return 0
 	}
-
-	if len(resp.Data) == 0 {
+	if doc == nil {
 		log.Printf("📦 Aucune donnée dans la réponse")
 		return 0
 	}
 
 	// Convertir la chaîne en int
-	page, err := strconv.Atoi(resp.Data[0].PageFetchedFrom)
+	page, err := strconv.Atoi(toString((*doc)[field]))
 	if err != nil {
-    	log.Printf("⚠️ Échec de la conversion de « page_fetched_from » en entier : %v", err)
+		log.Printf("⚠️ Échec de la conversion de « %s » en entier : %v", field, err)
 		return 0
 	}
 
 	log.Printf("📦 Dernière page récupérée: %d", page)
 	return page
-
 }
 
 type FilmStrapi struct {
 	IDFilm int `json:"id_film,string"` // <- string car id_film est une string dans le JSON
 }
 
-type ResponseStrapi struct {
-	Data []FilmStrapi `json:"data"`
-}
-
-func getFilmsByPageStrapi(page int) ([]int, error) {
-	strapiFilmURLWithPage := strapiFilmURL + "?filters[page_fetched_from][$eq]=" + strconv.Itoa(page)
-
-	req, err := http.NewRequest("GET", strapiFilmURLWithPage, nil)
-	if err != nil {
-		return nil, fmt.Errorf("❌ erreur création requête GET: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+strapiToken)
-
-	res, err := http.DefaultClient.Do(req)
+func getFilmsByPageStrapi(ctx context.Context, page int) ([]int, error) {
+	// All suit meta.pagination : une page TMDB peut correspondre à plus de lignes
+	// que la taille de page par défaut de Strapi
+	rows, err := collection[FilmStrapi](strapiFilms).All(ctx, strapi.NewQuery().Eq("page_fetched_from", page).Fields("id_film"))
 	if err != nil {
-		return nil, fmt.Errorf("❌ erreur d'appel HTTP: %w", err)
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("❌ réponse invalide: code %d", res.StatusCode)
-	}
-
-	var resp ResponseStrapi
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		return nil, fmt.Errorf("❌ erreur décodage JSON: %w", err)
+		return nil, err
 	}
 
 	var filmIDs []int
-	for _, film := range resp.Data {
-		//log.Printf("ID film: %d", film.IDFilm)
+	for _, film := range rows {
 		filmIDs = append(filmIDs, film.IDFilm)
 	}
 
@@ -224,40 +298,33 @@ type TvShowStrapi struct {
 	IDFilm int `json:"id_TvShow,string"` // <- string car id_film est une string dans le JSON
 }
 
-type ResponseTvShowStrapi struct {
-	Data []TvShowStrapi `json:"data"`
-}
-
-func getTvShowsByPageStrapi(page int) ([]int, error) {
-	strapiFilmURLWithPage := strapiTvShowURL + "?filters[page_fetched_from][$eq]=" + strconv.Itoa(page)
-
-	req, err := http.NewRequest("GET", strapiFilmURLWithPage, nil)
-	if err != nil {
-		return nil, fmt.Errorf("❌ erreur création requête GET: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+strapiToken)
-
-	res, err := http.DefaultClient.Do(req)
+func getTvShowsByPageStrapi(ctx context.Context, page int) ([]int, error) {
+	// All suit meta.pagination : une page TMDB peut correspondre à plus de lignes
+	// que la taille de page par défaut de Strapi
+	rows, err := collection[TvShowStrapi](strapiTvShows).All(ctx, strapi.NewQuery().Eq("page_fetched_from", page).Fields("id_TvShow"))
 	if err != nil {
-		return nil, fmt.Errorf("❌ erreur appel HTTP: %w", err)
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("❌ réponse invalide: code %d", res.StatusCode)
-	}
-
-	var resp ResponseTvShowStrapi
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		return nil, fmt.Errorf("❌ erreur décodage JSON: %w", err)
+		return nil, err
 	}
 
 	var filmIDs []int
-	for _, film := range resp.Data {
-		//log.Printf("ID film: %d", film.IDFilm)
+	for _, film := range rows {
 		filmIDs = append(filmIDs, film.IDFilm)
 	}
 
 	return filmIDs, nil
 }
+
+// toString convertit une valeur JSON décodée (string ou nombre) en chaîne
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
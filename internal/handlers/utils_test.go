@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"testing"
+)
+
+func TestNewLockerTTL(t *testing.T) {
+	tests := []struct {
+		ttl     string
+		wantErr bool
+	}{
+		{"", false},
+		{"10m", false},
+		{"30s", false},
+		{"29s", true},
+		{"1ns", true},
+		{"-1m", true},
+		{"dix minutes", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ttl, func(t *testing.T) {
+			t.Setenv("LOCK_BACKEND", "file")
+			t.Setenv("LOCK_DIR", t.TempDir())
+			t.Setenv("LOCK_TTL", tt.ttl)
+			_, ttl, err := newLocker()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newLocker() erreur = %v, attendu une erreur : %v", err, tt.wantErr)
+			}
+			if err == nil && ttl < minLockTTL {
+				t.Errorf("TTL = %s, inférieur à %s", ttl, minLockTTL)
+			}
+		})
+	}
+}
+
+func TestSharedBackends(t *testing.T) {
+	tests := []struct {
+		name           string
+		multi, lock    string
+		checkpoint     string
+		wantCheckpoint string
+		wantErr        bool
+	}{
+		{"une instance, défauts", "", "", "", "file", false},
+		{"verrou strapi : checkpoints strapi par défaut", "", "strapi", "", "strapi", false},
+		{"verrou postgres : checkpoints strapi par défaut", "", "postgres", "", "strapi", false},
+		{"checkpoints fichier explicites", "", "postgres", "file", "file", false},
+		{"plusieurs instances partagées", "true", "postgres", "", "strapi", false},
+		{"plusieurs instances avec un verrou fichier", "true", "", "", "file", true},
+		{"plusieurs instances avec des checkpoints fichier", "true", "strapi", "file", "file", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MULTI_INSTANCE", tt.multi)
+			t.Setenv("LOCK_BACKEND", tt.lock)
+			t.Setenv("CHECKPOINT_BACKEND", tt.checkpoint)
+			if got := checkpointBackend(); got != tt.wantCheckpoint {
+				t.Errorf("checkpointBackend() = %q, attendu %q", got, tt.wantCheckpoint)
+			}
+			if err := checkSharedBackends(); (err != nil) != tt.wantErr {
+				t.Errorf("checkSharedBackends() = %v, attendu une erreur : %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+//go:build unix
+
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// FileLocker pose un verrou flock(2) exclusif sur un fichier par verrou (<dir>/<name>.lock).
+// Le noyau libère le verrou quand le processus s'arrête : ttl n'est pas utilisé et un verrou
+// ne peut pas être repris par deux processus à la fois. Adapté aux instances qui partagent
+// une même machine.
+type FileLocker struct {
+	dir string
+}
+
+// fileLock est le contenu du fichier pendant que le verrou est tenu, pour renseigner LockedError.
+type fileLock struct {
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// NewFileLocker renvoie un FileLocker qui écrit ses verrous dans dir (créé au besoin).
+func NewFileLocker(dir string) *FileLocker {
+	return &FileLocker{dir: dir}
+}
+
+func (l *FileLocker) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (Lease, error) {
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("lock: %w", err)
+	}
+	path := filepath.Join(l.dir, name+".lock")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("lock: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		defer f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			held := &LockedError{Name: name}
+			var content fileLock
+			if b, err := os.ReadFile(path); err == nil && json.Unmarshal(b, &content) == nil {
+				held.Owner, held.Since = content.Owner, content.AcquiredAt
+			}
+			return nil, held
+		}
+		return nil, fmt.Errorf("lock: flock de %s: %w", path, err)
+	}
+
+	content, err := json.Marshal(fileLock{Owner: owner, AcquiredAt: time.Now().UTC()})
+	if err == nil {
+		if err = f.Truncate(0); err == nil {
+			_, err = f.WriteAt(content, 0)
+		}
+	}
+	if err != nil {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+		return nil, fmt.Errorf("lock: écriture de %s: %w", path, err)
+	}
+	return &fileLease{file: f}, nil
+}
+
+type fileLease struct {
+	file *os.File
+}
+
+// Refresh n'a rien à prolonger : le verrou est tenu tant que le fichier reste ouvert.
+func (l *fileLease) Refresh(ctx context.Context) error {
+	return nil
+}
+
+func (l *fileLease) Release(ctx context.Context) error {
+	defer l.file.Close()
+	// Le fichier est conservé : le supprimer laisserait un autre processus verrouiller
+	// l'ancien inode pendant qu'un troisième en crée un nouveau.
+	if err := l.file.Truncate(0); err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+	if err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN); err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build !unix
+
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// FileLocker repose sur flock(2), absent de cette plateforme : choisir le backend strapi
+// ou postgres.
+type FileLocker struct{}
+
+// NewFileLocker renvoie un FileLocker dont Acquire échoue toujours sur cette plateforme.
+func NewFileLocker(dir string) *FileLocker {
+	return &FileLocker{}
+}
+
+func (l *FileLocker) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (Lease, error) {
+	return nil, errors.New("lock: verrou fichier indisponible sur cette plateforme (flock), utiliser LOCK_BACKEND=strapi ou postgres")
+}
@@ -0,0 +1,44 @@
+// Package lock fournit un verrou partagé entre instances du serveur : une synchronisation
+// n'est exécutée que par l'instance qui détient son verrou. Plusieurs backends implémentent
+// Locker : fichier local (une seule machine), document Strapi avec expiration, ou verrou
+// consultatif Postgres.
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Locker distribue les verrous.
+type Locker interface {
+	// Acquire tente de prendre le verrou name pour owner sans attendre. Le verrou expire
+	// après ttl s'il n'est pas prolongé (voir Lease.Refresh), pour qu'une instance arrêtée
+	// en cours de route ne le garde pas indéfiniment. Si une autre instance le détient,
+	// l'erreur est une *LockedError.
+	Acquire(ctx context.Context, name, owner string, ttl time.Duration) (Lease, error)
+}
+
+// Lease est un verrou détenu.
+type Lease interface {
+	// Refresh prolonge le verrou de ttl. Une erreur signale que le verrou a pu être perdu.
+	Refresh(ctx context.Context) error
+	// Release libère le verrou s'il est toujours détenu.
+	Release(ctx context.Context) error
+}
+
+// LockedError signale un verrou détenu par une autre instance.
+type LockedError struct {
+	Name string
+	// Owner est le détenteur déclaré à Acquire (vide si le backend ne le connaît pas).
+	Owner string
+	// Since est la date de prise du verrou (zéro si le backend ne la connaît pas).
+	Since time.Time
+}
+
+func (e *LockedError) Error() string {
+	if e.Owner == "" {
+		return fmt.Sprintf("lock: %q détenu par une autre instance", e.Name)
+	}
+	return fmt.Sprintf("lock: %q détenu par %s", e.Name, e.Owner)
+}
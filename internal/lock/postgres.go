@@ -0,0 +1,78 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresLocker utilise les verrous consultatifs de session Postgres (pg_try_advisory_lock).
+// Le verrou est tenu par une connexion dédiée et disparaît avec elle : ttl n'est pas utilisé,
+// une instance arrêtée libère ses verrous dès que Postgres ferme sa session.
+type PostgresLocker struct {
+	db *sql.DB
+}
+
+// NewPostgresLocker ouvre la base dsn (ex: DATABASE_URL).
+func NewPostgresLocker(dsn string) (*PostgresLocker, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("lock: ouverture de Postgres: %w", err)
+	}
+	return &PostgresLocker{db: db}, nil
+}
+
+func (l *PostgresLocker) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (Lease, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lock: connexion Postgres: %w", err)
+	}
+	key := advisoryKey(name)
+	var ok bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("lock: pg_try_advisory_lock(%q): %w", name, err)
+	}
+	if !ok {
+		conn.Close()
+		return nil, &LockedError{Name: name}
+	}
+	return &postgresLease{conn: conn, name: name, key: key}, nil
+}
+
+// advisoryKey dérive la clé bigint du verrou consultatif à partir de son nom.
+func advisoryKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("mon-projet:" + name))
+	return int64(h.Sum64())
+}
+
+type postgresLease struct {
+	conn *sql.Conn
+	name string
+	key  int64
+}
+
+// Refresh vérifie que la session qui porte le verrou est toujours ouverte.
+func (l *postgresLease) Refresh(ctx context.Context) error {
+	if err := l.conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("lock: session Postgres du verrou %q perdue: %w", l.name, err)
+	}
+	return nil
+}
+
+func (l *postgresLease) Release(ctx context.Context) error {
+	defer l.conn.Close()
+	var ok bool
+	if err := l.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", l.key).Scan(&ok); err != nil {
+		return fmt.Errorf("lock: pg_advisory_unlock(%q): %w", l.name, err)
+	}
+	if !ok {
+		return fmt.Errorf("lock: verrou %q perdu", l.name)
+	}
+	return nil
+}
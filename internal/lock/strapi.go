@@ -0,0 +1,130 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mon-projet/internal/strapi"
+)
+
+// DefaultStrapiSettle est le délai entre l'écriture d'un verrou Strapi et sa relecture.
+const DefaultStrapiSettle = 2 * time.Second
+
+// StrapiLocker enregistre les verrous dans une collection Strapi (un document par verrou),
+// partagée par toutes les instances.
+//
+// Le verrou est « au mieux » : Strapi n'offrant pas d'écriture conditionnelle, deux instances
+// qui reprennent en même temps un verrou expiré écrivent chacune leur owner (unique par
+// exécution). Chacune relit le document après le délai settle et ne garde le verrou que si
+// son owner y figure encore : seule la dernière écriture l'emporte, tant que les deux écritures
+// ont lieu dans ce délai. Pour une exclusion stricte entre instances, préférer PostgresLocker.
+// Champs attendus : name (uid), owner, acquired_at, expires_at (datetime).
+type StrapiLocker struct {
+	collection *strapi.Collection[strapiLock]
+	settle     time.Duration
+}
+
+type strapiLock struct {
+	strapi.Entry
+	Name       string    `json:"name"`
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// NewStrapiLocker renvoie un StrapiLocker sur la collection name (ex: "sync-locks"),
+// qui relit chaque prise après DefaultStrapiSettle.
+func NewStrapiLocker(client *strapi.Client, name string) *StrapiLocker {
+	return &StrapiLocker{collection: strapi.NewCollection[strapiLock](client, name), settle: DefaultStrapiSettle}
+}
+
+func (l *StrapiLocker) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (Lease, error) {
+	doc, err := l.find(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if doc != nil && doc.Owner != owner && doc.ExpiresAt.After(now) {
+		return nil, &LockedError{Name: name, Owner: doc.Owner, Since: doc.AcquiredAt}
+	}
+
+	data := map[string]interface{}{
+		"name":        name,
+		"owner":       owner,
+		"acquired_at": now.Format(time.RFC3339),
+		"expires_at":  now.Add(ttl).Format(time.RFC3339),
+	}
+	if doc == nil {
+		_, err = l.collection.Create(ctx, data)
+	} else {
+		_, err = l.collection.Update(ctx, doc.Key(), data)
+	}
+	// name est unique : une création concurrente est refusée, la relecture tranche
+	if err != nil && !strapi.IsUniqueViolation(err, "name") {
+		return nil, fmt.Errorf("lock: écriture du verrou %q: %w", name, err)
+	}
+
+	// Une autre instance a pu écrire en même temps : on laisse passer ses écritures avant de relire
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(l.settle):
+	}
+	doc, err = l.find(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil || doc.Owner != owner {
+		held := &LockedError{Name: name}
+		if doc != nil {
+			held.Owner, held.Since = doc.Owner, doc.AcquiredAt
+		}
+		return nil, held
+	}
+	return &strapiLease{locker: l, doc: *doc, ttl: ttl}, nil
+}
+
+func (l *StrapiLocker) find(ctx context.Context, name string) (*strapiLock, error) {
+	doc, err := l.collection.First(ctx, strapi.NewQuery().Eq("name", name))
+	if err != nil {
+		return nil, fmt.Errorf("lock: lecture du verrou %q: %w", name, err)
+	}
+	return doc, nil
+}
+
+type strapiLease struct {
+	locker *StrapiLocker
+	doc    strapiLock
+	ttl    time.Duration
+}
+
+func (l *strapiLease) Refresh(ctx context.Context) error {
+	if err := l.check(ctx); err != nil {
+		return err
+	}
+	expires := time.Now().UTC().Add(l.ttl)
+	_, err := l.locker.collection.Update(ctx, l.doc.Key(), map[string]interface{}{
+		"expires_at": expires.Format(time.RFC3339),
+	})
+	return err
+}
+
+func (l *strapiLease) Release(ctx context.Context) error {
+	if err := l.check(ctx); err != nil {
+		return err
+	}
+	return l.locker.collection.Delete(ctx, l.doc.Key())
+}
+
+// check vérifie que le document porte toujours notre verrou (il a pu expirer et être repris).
+func (l *strapiLease) check(ctx context.Context) error {
+	doc, err := l.locker.find(ctx, l.doc.Name)
+	if err != nil {
+		return err
+	}
+	if doc == nil || doc.Owner != l.doc.Owner {
+		return fmt.Errorf("lock: verrou %q perdu", l.doc.Name)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"mon-projet/internal/lock"
+)
+
+// lockTimeout borne chaque appel au Locker.
+const lockTimeout = 30 * time.Second
+
+// Origines d'une exécution.
+const (
+	// TriggerCron : lancement planifié.
+	TriggerCron = "cron"
+	// TriggerManual : lancement demandé par une route HTTP.
+	TriggerManual = "manual"
+	// TriggerImport : lancement préalable à un import à la demande (voir handlers.ImportFilm).
+	TriggerImport = "import"
+)
+
+// Run décrit une exécution d'un job.
+type Run struct {
+	// ID identifie l'exécution dans les logs et les réponses HTTP (ex: "movies-20250101T120000Z-3").
+	ID      string `json:"id"`
+	Job     string `json:"job"`
+	Trigger string `json:"trigger"`
+	// Instance est l'instance du serveur qui exécute le job (voir UseLocker).
+	Instance  string    `json:"instance,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// AlreadyRunningError est renvoyée par Trigger quand le job est déjà en cours :
+// un même job ne tourne jamais deux fois en parallèle. Quand il tourne sur une autre
+// instance, Current n'est connu que par le détenteur du verrou (ID vide avec Postgres).
+type AlreadyRunningError struct {
+	Current Run
+}
+
+func (e *AlreadyRunningError) Error() string {
+	if e.Current.ID == "" {
+		return fmt.Sprintf("scheduler: job %q déjà en cours sur une autre instance", e.Current.Job)
+	}
+	return fmt.Sprintf("scheduler: job %q déjà en cours (run %s, démarré le %s)",
+		e.Current.Job, e.Current.ID, e.Current.StartedAt.Format(time.RFC3339))
+}
+
+// Trigger lance le job name en arrière-plan et renvoie son exécution. Si le job est déjà
+// en cours, sur cette instance ou (avec un Locker, voir UseLocker) sur une autre, rien n'est
+// lancé et l'erreur est une *AlreadyRunningError portant l'exécution en cours.
+func (s *Scheduler) Trigger(name, trigger string) (Run, error) {
+	job, run, lease, err := s.start(name, trigger)
+	if err != nil {
+		return Run{}, err
+	}
+	go s.execute(job, run, lease)
+	return run, nil
+}
+
+// Run exécute le job name dans la goroutine appelante, avec les mêmes garanties que Trigger,
+// et rend la main une fois le job terminé. Le Scheduler n'a pas besoin d'être démarré.
+func (s *Scheduler) Run(name, trigger string) (Run, error) {
+	job, run, lease, err := s.start(name, trigger)
+	if err != nil {
+		return Run{}, err
+	}
+	s.execute(job, run, lease)
+	return run, nil
+}
+
+// start marque le job name en cours et prend son verrou partagé.
+func (s *Scheduler) start(name, trigger string) (*Job, Run, lock.Lease, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	if !ok {
+		s.mu.Unlock()
+		return nil, Run{}, nil, fmt.Errorf("scheduler: job %q inconnu", name)
+	}
+	if job.running != nil {
+		current := *job.running
+		s.mu.Unlock()
+		return nil, Run{}, nil, &AlreadyRunningError{Current: current}
+	}
+	s.seq++
+	now := time.Now().UTC()
+	run := Run{
+		ID:        fmt.Sprintf("%s-%s-%d", name, now.Format("20060102T150405Z"), s.seq),
+		Job:       name,
+		Trigger:   trigger,
+		Instance:  s.instance,
+		StartedAt: now,
+	}
+	job.running = &run
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	lease, err := s.acquire(run)
+	if err != nil {
+		s.finish(job)
+		return nil, Run{}, nil, err
+	}
+	return job, run, lease, nil
+}
+
+// acquire prend le verrou partagé du job de run. Sans Locker, il n'y a rien à prendre.
+func (s *Scheduler) acquire(run Run) (lock.Lease, error) {
+	if s.locker == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+	lease, err := s.locker.Acquire(ctx, run.Job, run.ID+"@"+run.Instance, s.lockTTL)
+	var held *lock.LockedError
+	if errors.As(err, &held) {
+		// Le détenteur est "<run id>@<instance>" (voir ci-dessus), sauf pour Postgres
+		current := Run{Job: run.Job, StartedAt: held.Since}
+		current.ID, current.Instance, _ = strings.Cut(held.Owner, "@")
+		return nil, &AlreadyRunningError{Current: current}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scheduler: verrou du job %q: %w", run.Job, err)
+	}
+	return lease, nil
+}
+
+// finish marque job terminé.
+func (s *Scheduler) finish(job *Job) {
+	s.mu.Lock()
+	job.running = nil
+	s.mu.Unlock()
+	s.wg.Done()
+}
+
+// execute exécute job en prolongeant son verrou, puis le libère et marque le job terminé,
+// même si run panique.
+func (s *Scheduler) execute(job *Job, run Run, lease lock.Lease) {
+	log.Printf("🚀 Lancement de %s (%s, run %s)", job.Name, run.Trigger, run.ID)
+	stop := s.keepAlive(run, lease)
+	defer func() {
+		r := recover()
+		stop()
+		if lease != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+			if err := lease.Release(ctx); err != nil {
+				log.Printf("⚠️ Libération du verrou de %s (run %s): %v", job.Name, run.ID, err)
+			}
+			cancel()
+		}
+		s.finish(job)
+		if r != nil {
+			log.Printf("❌ Job %s (run %s) interrompu: %v", job.Name, run.ID, r)
+			return
+		}
+		log.Printf("🏁 Fin de %s (run %s) en %s", job.Name, run.ID, time.Since(run.StartedAt).Round(time.Second))
+	}()
+	job.Run()
+}
+
+// keepAlive prolonge le verrou de run tous les tiers de TTL (au plus une fois par seconde)
+// jusqu'à l'appel de la fonction renvoyée. Un échec est seulement signalé : la synchronisation
+// en cours n'est pas interrompue.
+func (s *Scheduler) keepAlive(run Run, lease lock.Lease) (stop func()) {
+	if lease == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(max(s.lockTTL/3, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+				if err := lease.Refresh(ctx); err != nil {
+					log.Printf("⚠️ Prolongation du verrou de %s (run %s): %v", run.Job, run.ID, err)
+				}
+				cancel()
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// runScheduled est appelée par cron : un lancement planifié est ignoré si le job tourne encore.
+func (s *Scheduler) runScheduled(name string) {
+	if _, err := s.Trigger(name, TriggerCron); err != nil {
+		log.Printf("⏭️ Lancement planifié ignoré: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package scheduler centralise la planification des synchronisations : chaque job est
+// enregistré par nom avec une expression cron (modifiable par configuration ou par
+// variable d'environnement), puis le Scheduler est démarré explicitement depuis main.
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+
+	"mon-projet/internal/lock"
+)
+
+// Disabled désactive la planification d'un job : il reste listé et déclenchable à la main.
+const Disabled = "off"
+
+// Job est un job enregistré.
+type Job struct {
+	// Name identifie le job (ex: "movies", "film-recommendations").
+	Name string
+	// Spec est l'expression cron retenue (ex: "0 * * * *"), ou Disabled.
+	Spec string
+	// Run exécute une synchronisation.
+	Run func()
+
+	entry   cron.EntryID
+	running *Run
+}
+
+// JobInfo décrit un job pour la liste des jobs (voir Scheduler.Jobs).
+type JobInfo struct {
+	Name    string     `json:"name"`
+	Spec    string     `json:"spec"`
+	NextRun *time.Time `json:"next_run,omitempty"`
+	PrevRun *time.Time `json:"prev_run,omitempty"`
+	// Running est l'exécution en cours, s'il y en a une.
+	Running *Run `json:"running,omitempty"`
+}
+
+// Scheduler planifie les jobs enregistrés. Il est sûr pour un usage concurrent.
+type Scheduler struct {
+	mu      sync.Mutex
+	cron    *cron.Cron
+	jobs    map[string]*Job
+	started bool
+	seq     int
+	wg      sync.WaitGroup
+
+	locker   lock.Locker
+	lockTTL  time.Duration
+	instance string
+}
+
+// New renvoie un Scheduler vide, non démarré.
+func New() *Scheduler {
+	return &Scheduler{cron: cron.New(), jobs: map[string]*Job{}}
+}
+
+// EnvVar renvoie la variable d'environnement qui remplace la planification du job name
+// (ex: "film-recommendations" → SCHEDULE_FILM_RECOMMENDATIONS).
+func EnvVar(name string) string {
+	return "SCHEDULE_" + strings.ToUpper(strings.NewReplacer("-", "_", ":", "_").Replace(name))
+}
+
+// Register enregistre le job name planifié selon spec (voir Trigger : un lancement
+// planifié est ignoré tant que l'exécution précédente n'est pas terminée). La variable EnvVar(name), si elle
+// est renseignée, remplace spec. Une spec Disabled enregistre le job sans le planifier.
+func (s *Scheduler) Register(name, spec string, run func()) error {
+	if env := os.Getenv(EnvVar(name)); env != "" {
+		spec = env
+	}
+	spec = strings.TrimSpace(spec)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("scheduler: job %q enregistré deux fois", name)
+	}
+	job := &Job{Name: name, Spec: spec, Run: run}
+	if spec != Disabled {
+		id, err := s.cron.AddFunc(spec, func() { s.runScheduled(name) })
+		if err != nil {
+			return fmt.Errorf("scheduler: job %q, expression cron %q invalide: %w", name, spec, err)
+		}
+		job.entry = id
+	}
+	s.jobs[name] = job
+	return nil
+}
+
+// UseLocker impose à chaque exécution de prendre d'abord le verrou partagé du job dans
+// locker : une seule instance exécute un job donné à la fois. instance identifie ce serveur
+// auprès des autres ; ttl est la durée du verrou, prolongé tant que le job tourne.
+// À appeler avant Start.
+func (s *Scheduler) UseLocker(locker lock.Locker, ttl time.Duration, instance string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locker, s.lockTTL, s.instance = locker, ttl, instance
+}
+
+// Job renvoie le job name, ou nil s'il n'est pas enregistré.
+func (s *Scheduler) Job(name string) *Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[name]
+}
+
+// Start démarre la planification. Les appels suivants sont sans effet.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	s.cron.Start()
+	log.Printf("🕒 Scheduler démarré : %d jobs enregistrés", len(s.jobs))
+}
+
+// Stop arrête la planification et attend la fin des exécutions en cours.
+func (s *Scheduler) Stop() {
+	<-s.cron.Stop().Done()
+	s.wg.Wait()
+}
+
+// Jobs renvoie les jobs enregistrés triés par nom, avec leurs prochaine et précédente
+// exécutions planifiées (vides si le job est désactivé ou le Scheduler non démarré).
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]JobInfo, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		info := JobInfo{Name: job.Name, Spec: job.Spec}
+		if job.running != nil {
+			current := *job.running
+			info.Running = &current
+		}
+		if job.entry != 0 {
+			entry := s.cron.Entry(job.entry)
+			info.NextRun, info.PrevRun = timeOrNil(entry.Next), timeOrNil(entry.Prev)
+		}
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,242 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mon-projet/internal/lock"
+)
+
+// blockingJob enregistre le job name dans s : chaque exécution signale son départ sur started
+// puis attend la fermeture de release.
+func blockingJob(t *testing.T, s *Scheduler, name string) (started chan struct{}, release chan struct{}, runs *int) {
+	t.Helper()
+	started, release = make(chan struct{}, 10), make(chan struct{})
+	var mu sync.Mutex
+	runs = new(int)
+	err := s.Register(name, Disabled, func() {
+		mu.Lock()
+		*runs++
+		mu.Unlock()
+		started <- struct{}{}
+		<-release
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return started, release, runs
+}
+
+func TestTriggerConcurrentRunsOnce(t *testing.T) {
+	s := New()
+	started, release, runs := blockingJob(t, s, "movies")
+
+	const callers = 8
+	var wg sync.WaitGroup
+	runsOut := make([]Run, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runsOut[i], errs[i] = s.Trigger("movies", TriggerManual)
+		}()
+	}
+	wg.Wait()
+	<-started
+
+	var launched Run
+	var running []*AlreadyRunningError
+	for i, err := range errs {
+		var already *AlreadyRunningError
+		switch {
+		case err == nil:
+			if launched.ID != "" {
+				t.Fatalf("deux exécutions lancées : %s et %s", launched.ID, runsOut[i].ID)
+			}
+			launched = runsOut[i]
+		case errors.As(err, &already):
+			running = append(running, already)
+		default:
+			t.Fatalf("Trigger: %v", err)
+		}
+	}
+	if launched.ID == "" {
+		t.Fatal("aucune exécution lancée")
+	}
+	if len(running) != callers-1 {
+		t.Fatalf("%d appels refusés, attendu %d", len(running), callers-1)
+	}
+	for _, already := range running {
+		if already.Current.ID != launched.ID || already.Current.Trigger != TriggerManual {
+			t.Errorf("exécution en cours = %+v, attendu %+v", already.Current, launched)
+		}
+	}
+	if info := s.Jobs()[0]; info.Running == nil || info.Running.ID != launched.ID {
+		t.Errorf("Jobs() running = %+v, attendu %s", info.Running, launched.ID)
+	}
+
+	close(release)
+	s.Stop()
+	if *runs != 1 {
+		t.Errorf("%d exécutions, attendu 1", *runs)
+	}
+	if info := s.Jobs()[0]; info.Running != nil {
+		t.Errorf("Jobs() running = %+v après la fin du job", info.Running)
+	}
+}
+
+func TestRunWaitsAndRefusesWhileRunning(t *testing.T) {
+	s := New()
+	started, release, _ := blockingJob(t, s, "genres")
+
+	triggered, err := s.Trigger("genres", TriggerCron)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	_, err = s.Run("genres", TriggerImport)
+	var already *AlreadyRunningError
+	if !errors.As(err, &already) || already.Current.ID != triggered.ID {
+		t.Fatalf("Run pendant une exécution = %v, attendu AlreadyRunningError(%s)", err, triggered.ID)
+	}
+	close(release)
+	s.Stop()
+
+	// Run est synchrone : le job est terminé quand Run rend la main
+	done := false
+	if err := s.Register("configuration", Disabled, func() { done = true }); err != nil {
+		t.Fatal(err)
+	}
+	run, err := s.Run("configuration", TriggerImport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done || run.Job != "configuration" || run.Trigger != TriggerImport {
+		t.Errorf("Run = %+v, job exécuté : %v", run, done)
+	}
+}
+
+func TestTriggerAfterPanic(t *testing.T) {
+	s := New()
+	calls := 0
+	if err := s.Register("videos", Disabled, func() {
+		calls++
+		panic("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := s.Run("videos", TriggerManual); err != nil {
+			t.Fatalf("Run après une panique : %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("%d exécutions, attendu 2", calls)
+	}
+}
+
+func TestTriggerUnknownJob(t *testing.T) {
+	if _, err := New().Trigger("inconnu", TriggerManual); err == nil {
+		t.Error("Trigger sur un job inconnu : pas d'erreur")
+	}
+}
+
+func TestAlreadyRunningErrorMessage(t *testing.T) {
+	startedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		current Run
+		want    string
+	}{
+		{
+			name:    "exécution connue",
+			current: Run{ID: "movies-20250101T120000Z-3", Job: "movies", StartedAt: startedAt},
+			want:    `scheduler: job "movies" déjà en cours (run movies-20250101T120000Z-3, démarré le 2025-01-01T12:00:00Z)`,
+		},
+		{
+			name:    "autre instance sans identifiant",
+			current: Run{Job: "movies"},
+			want:    `scheduler: job "movies" déjà en cours sur une autre instance`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&AlreadyRunningError{Current: tt.current}).Error(); got != tt.want {
+				t.Errorf("Error() = %q, attendu %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// heldLocker est un Locker dont le verrou est toujours tenu par owner
+type heldLocker struct {
+	owner string
+	since time.Time
+}
+
+func (l heldLocker) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (lock.Lease, error) {
+	return nil, &lock.LockedError{Name: name, Owner: l.owner, Since: l.since}
+}
+
+func TestTriggerLockedByAnotherInstance(t *testing.T) {
+	since := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		owner string
+		want  Run
+	}{
+		{"verrou Strapi ou fichier", "movies-20250101T120000Z-7@web-2", Run{ID: "movies-20250101T120000Z-7", Job: "movies", Instance: "web-2", StartedAt: since}},
+		{"verrou Postgres sans détenteur", "", Run{Job: "movies", StartedAt: since}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			s.UseLocker(heldLocker{owner: tt.owner, since: since}, time.Minute, "web-1")
+			ran := false
+			if err := s.Register("movies", Disabled, func() { ran = true }); err != nil {
+				t.Fatal(err)
+			}
+			_, err := s.Trigger("movies", TriggerManual)
+			var already *AlreadyRunningError
+			if !errors.As(err, &already) {
+				t.Fatalf("Trigger = %v, attendu AlreadyRunningError", err)
+			}
+			if already.Current != tt.want {
+				t.Errorf("exécution en cours = %+v, attendu %+v", already.Current, tt.want)
+			}
+			s.Stop()
+			if ran {
+				t.Error("le job a tourné sans son verrou")
+			}
+			if info := s.Jobs()[0]; info.Running != nil {
+				t.Errorf("Jobs() running = %+v sans verrou", info.Running)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	t.Setenv(EnvVar("film-recommendations"), "0 3 * * *")
+	s := New()
+	if err := s.Register("film-recommendations", "0 0 * * *", func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Register("film-recommendations", "0 0 * * *", func() {}); err == nil {
+		t.Error("job enregistré deux fois : pas d'erreur")
+	}
+	if err := s.Register("movies", "toutes les heures", func() {}); err == nil || !strings.Contains(err.Error(), "invalide") {
+		t.Errorf("expression cron invalide : %v", err)
+	}
+	if got := s.Job("film-recommendations").Spec; got != "0 3 * * *" {
+		t.Errorf("Spec = %q, attendu la valeur de %s", got, EnvVar("film-recommendations"))
+	}
+	if got := EnvVar("credits:movie"); got != "SCHEDULE_CREDITS_MOVIE" {
+		t.Errorf("EnvVar = %q", got)
+	}
+}
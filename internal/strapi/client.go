@@ -0,0 +1,115 @@
+// Package strapi est un client REST pour les collections Strapi (v4 et v5).
+// Il construit les query strings (filtres, tri, pagination), décode l'enveloppe
+// {"data", "meta"} et remonte l'enveloppe {"error"} sous forme d'erreur Go typée.
+package strapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout borne la durée d'un appel Strapi.
+	DefaultTimeout = 15 * time.Second
+	// DefaultPageSize est la taille de page utilisée pour parcourir une collection.
+	// Strapi plafonne pageSize à 100 par défaut (api.rest.maxLimit).
+	DefaultPageSize = 100
+)
+
+// Client porte l'URL et le jeton d'une instance Strapi.
+type Client struct {
+	// BaseURL de Strapi, sans /api (ex: https://cms.example.com).
+	BaseURL string
+	// Token est le jeton API envoyé en Bearer.
+	Token string
+	// HTTPClient permet d'injecter un client (timeouts, transport de test…).
+	HTTPClient *http.Client
+	// PageSize est la taille de page utilisée par Iter et All
+	// quand la Query ne précise pas pagination[pageSize].
+	PageSize int
+}
+
+// New construit un Client pour baseURL avec le jeton token.
+func New(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		PageSize:   DefaultPageSize,
+	}
+}
+
+// NewFromEnv construit un Client à partir de STRAPI_URL, STRAPI_TOKEN et STRAPI_PAGE_SIZE.
+func NewFromEnv() *Client {
+	c := New(os.Getenv("STRAPI_URL"), os.Getenv("STRAPI_TOKEN"))
+	if size, err := strconv.Atoi(os.Getenv("STRAPI_PAGE_SIZE")); err == nil && size > 0 {
+		c.PageSize = size
+	}
+	return c
+}
+
+// do exécute une requête sur /api/<path> et décode le corps dans out (si non nil).
+// Une réponse >= 400 est convertie en *Error.
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, body, out interface{}) error {
+	reqURL := c.BaseURL + "/api/" + strings.TrimLeft(path, "/")
+	if len(params) > 0 {
+		reqURL += "?" + params.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("strapi %s %s: encodage JSON: %w", method, path, err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reader)
+	if err != nil {
+		return fmt.Errorf("strapi %s %s: création de la requête: %w", method, path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("strapi %s %s: %w", method, path, err)
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("strapi %s %s: lecture de la réponse: %w", method, path, err)
+	}
+
+	if res.StatusCode >= 400 {
+		return decodeError(method, path, res.StatusCode, raw)
+	}
+
+	if out == nil || len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("strapi %s %s: décodage JSON: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package strapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Pagination reprend meta.pagination (pagination par page ou par offset).
+type Pagination struct {
+	Page      int `json:"page"`
+	PageSize  int `json:"pageSize"`
+	PageCount int `json:"pageCount"`
+	Total     int `json:"total"`
+	Start     int `json:"start"`
+	Limit     int `json:"limit"`
+}
+
+// Meta reprend le champ meta d'une réponse de liste.
+type Meta struct {
+	Pagination Pagination `json:"pagination"`
+}
+
+// List est le résultat d'un Find : les documents et la pagination.
+type List[T any] struct {
+	Data []T
+	Meta Meta
+}
+
+// Document est un document Strapi sans schéma, pratique pour les comparaisons de champs.
+type Document map[string]interface{}
+
+// DocumentID renvoie le documentId (Strapi v5) ou, à défaut, l'id numérique (v4).
+func (d Document) DocumentID() string {
+	if id, ok := d["documentId"].(string); ok && id != "" {
+		return id
+	}
+	if id, ok := d["id"]; ok && id != nil {
+		return fmt.Sprint(id)
+	}
+	return ""
+}
+
+// Entry reprend les identifiants d'un document, à embarquer dans les types décodés
+// par Collection : id (numérique, v4) et documentId (v5).
+type Entry struct {
+	ID         int    `json:"id"`
+	DocumentID string `json:"documentId"`
+}
+
+// Key renvoie l'identifiant à utiliser dans les URL : le documentId (v5) ou, à défaut,
+// l'id numérique (v4), comme Document.DocumentID.
+func (e Entry) Key() string {
+	if e.DocumentID != "" {
+		return e.DocumentID
+	}
+	if e.ID != 0 {
+		return strconv.Itoa(e.ID)
+	}
+	return ""
+}
+
+// Collection est un client typé pour une collection Strapi (ex: "films").
+// T est le type dans lequel chaque document est décodé.
+type Collection[T any] struct {
+	client *Client
+	name   string
+	locale string
+}
+
+// NewCollection renvoie le client de la collection name (identifiant pluriel de l'API).
+func NewCollection[T any](client *Client, name string) *Collection[T] {
+	return &Collection[T]{client: client, name: name}
+}
+
+// Localized renvoie une copie de la collection dont toutes les requêtes portent
+// locale=locale (plugin i18n). En v5, Update sur un documentId crée la localisation
+// si elle n'existe pas encore.
+func (c *Collection[T]) Localized(locale string) *Collection[T] {
+	return &Collection[T]{client: c.client, name: c.name, locale: locale}
+}
+
+// params ajoute la locale de la collection aux paramètres de q.
+func (c *Collection[T]) params(q *Query) url.Values {
+	values := q.Values()
+	if c.locale != "" {
+		values.Set("locale", c.locale)
+	}
+	return values
+}
+
+// Name renvoie l'identifiant pluriel de la collection.
+func (c *Collection[T]) Name() string {
+	return c.name
+}
+
+// Find liste les documents correspondant à q (une page).
+func (c *Collection[T]) Find(ctx context.Context, q *Query) (*List[T], error) {
+	var env struct {
+		Data []json.RawMessage `json:"data"`
+		Meta Meta              `json:"meta"`
+	}
+	if err := c.client.do(ctx, "GET", c.name, c.params(q), nil, &env); err != nil {
+		return nil, err
+	}
+	out := &List[T]{Meta: env.Meta, Data: make([]T, 0, len(env.Data))}
+	for _, raw := range env.Data {
+		var item T
+		if err := decodeDocument(raw, &item); err != nil {
+			return nil, fmt.Errorf("strapi GET %s: %w", c.name, err)
+		}
+		out.Data = append(out.Data, item)
+	}
+	return out, nil
+}
+
+// First renvoie le premier document correspondant à q, ou nil s'il n'y en a aucun.
+func (c *Collection[T]) First(ctx context.Context, q *Query) (*T, error) {
+	list, err := c.Find(ctx, q.Clone().Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Data) == 0 {
+		return nil, nil
+	}
+	return &list.Data[0], nil
+}
+
+// FindOne renvoie le document documentID (id numérique en v4).
+func (c *Collection[T]) FindOne(ctx context.Context, documentID string, q *Query) (*T, error) {
+	return c.single(ctx, "GET", c.name+"/"+url.PathEscape(documentID), c.params(q), nil)
+}
+
+// Create crée un document à partir de data (enveloppé dans {"data": ...}).
+func (c *Collection[T]) Create(ctx context.Context, data interface{}) (*T, error) {
+	return c.single(ctx, "POST", c.name, c.params(nil), map[string]interface{}{"data": data})
+}
+
+// Update met à jour le document documentID avec les champs de data.
+func (c *Collection[T]) Update(ctx context.Context, documentID string, data interface{}) (*T, error) {
+	return c.single(ctx, "PUT", c.name+"/"+url.PathEscape(documentID), c.params(nil), map[string]interface{}{"data": data})
+}
+
+// Delete supprime le document documentID.
+func (c *Collection[T]) Delete(ctx context.Context, documentID string) error {
+	return c.client.do(ctx, "DELETE", c.name+"/"+url.PathEscape(documentID), c.params(nil), nil, nil)
+}
+
+// Count renvoie le nombre total de documents correspondant à q.
+func (c *Collection[T]) Count(ctx context.Context, q *Query) (int, error) {
+	var env struct {
+		Meta Meta `json:"meta"`
+	}
+	params := c.params(q.Clone().Page(1, 1))
+	if err := c.client.do(ctx, "GET", c.name, params, nil, &env); err != nil {
+		return 0, err
+	}
+	return env.Meta.Pagination.Total, nil
+}
+
+func (c *Collection[T]) single(ctx context.Context, method, path string, params url.Values, body interface{}) (*T, error) {
+	var env struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := c.client.do(ctx, method, path, params, body, &env); err != nil {
+		return nil, err
+	}
+	if len(env.Data) == 0 || string(env.Data) == "null" {
+		return nil, nil
+	}
+	var item T
+	if err := decodeDocument(env.Data, &item); err != nil {
+		return nil, fmt.Errorf("strapi %s %s: %w", method, path, err)
+	}
+	return &item, nil
+}
+
+// decodeDocument décode un document v5 (attributs à plat) ou v4 ({"id", "attributes"}).
+func decodeDocument(raw json.RawMessage, out interface{}) error {
+	var v4 struct {
+		ID         json.RawMessage            `json:"id"`
+		Attributes map[string]json.RawMessage `json:"attributes"`
+	}
+	if err := json.Unmarshal(raw, &v4); err == nil && v4.Attributes != nil {
+		flat := v4.Attributes
+		if len(v4.ID) > 0 {
+			flat["id"] = v4.ID
+		}
+		b, err := json.Marshal(flat)
+		if err != nil {
+			return err
+		}
+		raw = b
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("décodage du document: %w", err)
+	}
+	return nil
+}
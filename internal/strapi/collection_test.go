@@ -0,0 +1,70 @@
+package strapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type film struct {
+	Entry
+	Title  string `json:"title"`
+	IDFilm int    `json:"id_film"`
+}
+
+func TestDecodeDocument(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want film
+		key  string
+	}{
+		{
+			name: "v5 à plat",
+			raw:  `{"id": 12, "documentId": "abc", "title": "Fight Club", "id_film": 550}`,
+			want: film{Entry: Entry{ID: 12, DocumentID: "abc"}, Title: "Fight Club", IDFilm: 550},
+			key:  "abc",
+		},
+		{
+			name: "v4 avec attributes",
+			raw:  `{"id": 12, "attributes": {"title": "Fight Club", "id_film": 550}}`,
+			want: film{Entry: Entry{ID: 12}, Title: "Fight Club", IDFilm: 550},
+			key:  "12",
+		},
+		{
+			name: "v4 avec attributes vides",
+			raw:  `{"id": 7, "attributes": {}}`,
+			want: film{Entry: Entry{ID: 7}},
+			key:  "7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got film
+			if err := decodeDocument(json.RawMessage(tt.raw), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("decodeDocument = %+v, attendu %+v", got, tt.want)
+			}
+			if got.Key() != tt.key {
+				t.Errorf("Key() = %q, attendu %q", got.Key(), tt.key)
+			}
+
+			var doc Document
+			if err := decodeDocument(json.RawMessage(tt.raw), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if doc.DocumentID() != tt.key {
+				t.Errorf("Document.DocumentID() = %q, attendu %q", doc.DocumentID(), tt.key)
+			}
+			if _, ok := doc["attributes"]; ok {
+				t.Errorf("Document v4 non aplati: %v", doc)
+			}
+		})
+	}
+
+	var got film
+	if err := decodeDocument(json.RawMessage(`{"title": 3}`), &got); err == nil {
+		t.Error("decodeDocument sur un type invalide: pas d'erreur")
+	}
+}
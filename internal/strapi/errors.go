@@ -0,0 +1,99 @@
+package strapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error reprend l'enveloppe {"error": {...}} renvoyée par Strapi.
+type Error struct {
+	Method  string
+	Path    string
+	Status  int          `json:"status"`
+	Name    string       `json:"name"`
+	Message string       `json:"message"`
+	Details ErrorDetails `json:"details"`
+	// Body contient la réponse brute quand elle ne suit pas l'enveloppe Strapi.
+	Body string
+}
+
+// ErrorDetails contient les erreurs de validation éventuelles (ValidationError).
+type ErrorDetails struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// FieldError décrit une erreur de validation sur un champ.
+type FieldError struct {
+	Path    []string `json:"path"`
+	Message string   `json:"message"`
+	Name    string   `json:"name"`
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Body
+	}
+	s := fmt.Sprintf("strapi %s %s: code %d", e.Method, e.Path, e.Status)
+	if e.Name != "" {
+		s += " " + e.Name
+	}
+	if msg != "" {
+		s += ": " + msg
+	}
+	for _, fe := range e.Details.Errors {
+		s += fmt.Sprintf(" [%s: %s]", strings.Join(fe.Path, "."), fe.Message)
+	}
+	return s
+}
+
+// IsValidation indique si l'erreur est une ValidationError Strapi (ex: champ unique déjà pris).
+func (e *Error) IsValidation() bool {
+	return e.Name == "ValidationError" || e.Status == http.StatusBadRequest
+}
+
+// IsNotFound indique si err est une erreur Strapi 404.
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Status == http.StatusNotFound
+}
+
+// IsValidation indique si err est une ValidationError Strapi.
+func IsValidation(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.IsValidation()
+}
+
+// IsUniqueViolation indique si err est une ValidationError Strapi signalant que la valeur
+// du champ field est déjà prise par un autre document (attribut unique).
+func IsUniqueViolation(err error, field string) bool {
+	var e *Error
+	if !errors.As(err, &e) || !e.IsValidation() {
+		return false
+	}
+	for _, fe := range e.Details.Errors {
+		if len(fe.Path) > 0 && fe.Path[len(fe.Path)-1] == field &&
+			strings.Contains(strings.ToLower(fe.Message), "unique") {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeError(method, path string, status int, raw []byte) error {
+	apiErr := &Error{Method: method, Path: path, Status: status}
+	var env struct {
+		Error *Error `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &env); err == nil && env.Error != nil {
+		apiErr.Name = env.Error.Name
+		apiErr.Message = env.Error.Message
+		apiErr.Details = env.Error.Details
+		return apiErr
+	}
+	apiErr.Body = strings.TrimSpace(string(raw))
+	return apiErr
+}
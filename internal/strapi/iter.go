@@ -0,0 +1,58 @@
+package strapi
+
+import (
+	"context"
+	"iter"
+	"strconv"
+)
+
+// Iter parcourt tous les documents correspondant à q, page par page
+// (pagination[page]/pageSize), jusqu'à meta.pagination.pageCount.
+// La première erreur rencontrée est renvoyée puis l'itération s'arrête.
+//
+//	for film, err := range films.Iter(ctx, q) { ... }
+func (c *Collection[T]) Iter(ctx context.Context, q *Query) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		pageSize := c.client.PageSize
+		if size, err := strconv.Atoi(q.Values().Get("pagination[pageSize]")); err == nil && size > 0 {
+			pageSize = size
+		}
+		if pageSize <= 0 {
+			pageSize = DefaultPageSize
+		}
+
+		for page := 1; ; page++ {
+			list, err := c.Find(ctx, q.Clone().Page(page, pageSize))
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range list.Data {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			// pageCount absent (réponse non paginée) : on s'arrête sur une page incomplète
+			if list.Meta.Pagination.PageCount > 0 {
+				if page >= list.Meta.Pagination.PageCount {
+					return
+				}
+			} else if len(list.Data) < pageSize {
+				return
+			}
+		}
+	}
+}
+
+// All renvoie tous les documents correspondant à q en parcourant toutes les pages.
+func (c *Collection[T]) All(ctx context.Context, q *Query) ([]T, error) {
+	var out []T
+	for item, err := range c.Iter(ctx, q) {
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
@@ -0,0 +1,114 @@
+package strapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// pagedServer sert total films de /api/films, page par page, et compte les pages demandées.
+// Sans pageCount, la réponse imite une API qui ne renvoie pas meta.pagination.
+func pagedServer(t *testing.T, total int, pageCount bool) (*Client, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/films" {
+			http.NotFound(w, r)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("pagination[page]"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("pagination[pageSize]"))
+		mu.Lock()
+		pages = append(pages, fmt.Sprintf("%d/%d", page, size))
+		mu.Unlock()
+
+		data := []map[string]interface{}{}
+		for i := (page-1)*size + 1; i <= min(page*size, total); i++ {
+			data = append(data, map[string]interface{}{"id": i, "documentId": fmt.Sprintf("doc-%d", i), "id_film": i})
+		}
+		meta := map[string]interface{}{}
+		if pageCount {
+			meta["pagination"] = map[string]int{"page": page, "pageSize": size, "pageCount": (total + size - 1) / size, "total": total}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "meta": meta})
+	}))
+	t.Cleanup(srv.Close)
+	return New(srv.URL, "token"), &pages
+}
+
+func TestAllPagination(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		pageCount bool
+		q         *Query
+		clientPS  int
+		wantPages []string
+	}{
+		{"pageCount", 5, true, NewQuery(), 2, []string{"1/2", "2/2", "3/2"}},
+		{"pageCount sur un multiple de la page", 4, true, NewQuery(), 2, []string{"1/2", "2/2"}},
+		{"collection vide", 0, true, NewQuery(), 2, []string{"1/2"}},
+		{"sans pageCount : arrêt sur une page incomplète", 5, false, NewQuery(), 2, []string{"1/2", "2/2", "3/2"}},
+		{"sans pageCount : arrêt sur une page vide", 4, false, NewQuery(), 2, []string{"1/2", "2/2", "3/2"}},
+		{"pageSize de la Query prioritaire", 5, true, NewQuery().Page(9, 3), 2, []string{"1/3", "2/3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, pages := pagedServer(t, tt.total, tt.pageCount)
+			client.PageSize = tt.clientPS
+			films, err := NewCollection[film](client, "films").All(context.Background(), tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(films) != tt.total {
+				t.Fatalf("All = %d films, attendu %d", len(films), tt.total)
+			}
+			for i, f := range films {
+				if f.IDFilm != i+1 || f.Key() != fmt.Sprintf("doc-%d", i+1) {
+					t.Errorf("films[%d] = %+v", i, f)
+				}
+			}
+			if fmt.Sprint(*pages) != fmt.Sprint(tt.wantPages) {
+				t.Errorf("pages demandées = %v, attendu %v", *pages, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestIterStopsOnBreak(t *testing.T) {
+	client, pages := pagedServer(t, 10, true)
+	client.PageSize = 2
+	n := 0
+	for _, err := range NewCollection[film](client, "films").Iter(context.Background(), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 3 {
+			break
+		}
+	}
+	if len(*pages) != 2 {
+		t.Errorf("pages demandées = %v, attendu 2 pages", *pages)
+	}
+}
+
+func TestIterReturnsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"data": null, "error": {"status": 403, "name": "ForbiddenError", "message": "Forbidden"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewCollection[film](New(srv.URL, ""), "films").All(context.Background(), nil)
+	var strapiErr *Error
+	if !errors.As(err, &strapiErr) || strapiErr.Status != http.StatusForbidden {
+		t.Errorf("All = %v, attendu une *Error 403", err)
+	}
+}
@@ -0,0 +1,129 @@
+package strapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Query construit la query string d'une lecture Strapi :
+// filters[...], sort, pagination[...], fields[...] et populate.
+// Les méthodes renvoient la Query pour pouvoir être chaînées.
+type Query struct {
+	values url.Values
+	sorts  int
+	fields int
+}
+
+// NewQuery renvoie une Query vide.
+func NewQuery() *Query {
+	return &Query{values: url.Values{}}
+}
+
+// Filter ajoute filters[field][op]=value (ex: Filter("id_film", "$eq", 550)).
+// field peut être un chemin imbriqué séparé par des points (ex: "genre.id_genre").
+func (q *Query) Filter(field, op string, value interface{}) *Query {
+	q.values.Set(filterKey(field)+"["+op+"]", fmt.Sprint(value))
+	return q
+}
+
+// Eq est un raccourci pour Filter(field, "$eq", value).
+func (q *Query) Eq(field string, value interface{}) *Query {
+	return q.Filter(field, "$eq", value)
+}
+
+// Related filtre sur le document lié par relation : par documentId quand doc en a un (v5),
+// sinon par id numérique (v4, où les filtres ne connaissent pas documentId).
+func (q *Query) Related(relation string, doc Document) *Query {
+	if id, ok := doc["documentId"].(string); ok && id != "" {
+		return q.Eq(relation+".documentId", id)
+	}
+	return q.Eq(relation+".id", doc.DocumentID())
+}
+
+// In ajoute filters[field][$in][i]=value pour chaque valeur.
+func (q *Query) In(field string, values ...interface{}) *Query {
+	key := filterKey(field) + "[$in]"
+	for i, v := range values {
+		q.values.Set(key+"["+strconv.Itoa(i)+"]", fmt.Sprint(v))
+	}
+	return q
+}
+
+// Sort ajoute un critère de tri (ex: "page_fetched_from:desc").
+func (q *Query) Sort(sort string) *Query {
+	q.values.Set("sort["+strconv.Itoa(q.sorts)+"]", sort)
+	q.sorts++
+	return q
+}
+
+// Page demande la page page (à partir de 1) avec pageSize éléments.
+func (q *Query) Page(page, pageSize int) *Query {
+	q.values.Del("pagination[start]")
+	q.values.Del("pagination[limit]")
+	q.values.Set("pagination[page]", strconv.Itoa(page))
+	if pageSize > 0 {
+		q.values.Set("pagination[pageSize]", strconv.Itoa(pageSize))
+	}
+	return q
+}
+
+// Limit demande au plus limit éléments (pagination par offset).
+func (q *Query) Limit(limit int) *Query {
+	q.values.Del("pagination[page]")
+	q.values.Del("pagination[pageSize]")
+	q.values.Set("pagination[limit]", strconv.Itoa(limit))
+	return q
+}
+
+// Fields restreint les attributs renvoyés.
+func (q *Query) Fields(fields ...string) *Query {
+	for _, f := range fields {
+		q.values.Set("fields["+strconv.Itoa(q.fields)+"]", f)
+		q.fields++
+	}
+	return q
+}
+
+// Populate demande le peuplement des relations données (ou "*" pour toutes).
+func (q *Query) Populate(relations ...string) *Query {
+	for _, r := range relations {
+		if r == "*" {
+			q.values.Set("populate", "*")
+			continue
+		}
+		q.values.Set("populate["+r+"]", "true")
+	}
+	return q
+}
+
+// Set positionne un paramètre brut, pour les cas non couverts par le builder.
+func (q *Query) Set(key, value string) *Query {
+	q.values.Set(key, value)
+	return q
+}
+
+// Values renvoie une copie des paramètres de la requête.
+func (q *Query) Values() url.Values {
+	out := url.Values{}
+	if q == nil {
+		return out
+	}
+	for k, v := range q.values {
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+}
+
+// Clone renvoie une copie indépendante de la Query.
+func (q *Query) Clone() *Query {
+	if q == nil {
+		return NewQuery()
+	}
+	return &Query{values: q.Values(), sorts: q.sorts, fields: q.fields}
+}
+
+func filterKey(field string) string {
+	return "filters[" + strings.ReplaceAll(field, ".", "][") + "]"
+}
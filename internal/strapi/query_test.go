@@ -0,0 +1,93 @@
+package strapi
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestQueryValues(t *testing.T) {
+	tests := []struct {
+		name string
+		q    *Query
+		want url.Values
+	}{
+		{"vide", NewQuery(), url.Values{}},
+		{"nil", nil, url.Values{}},
+		{
+			name: "filtre simple",
+			q:    NewQuery().Eq("id_film", 550),
+			want: url.Values{"filters[id_film][$eq]": {"550"}},
+		},
+		{
+			name: "filtre imbriqué",
+			q:    NewQuery().Filter("genre.id_genre", "$ne", 18),
+			want: url.Values{"filters[genre][id_genre][$ne]": {"18"}},
+		},
+		{
+			name: "filtre $in",
+			q:    NewQuery().In("id_film", 1, 2),
+			want: url.Values{"filters[id_film][$in][0]": {"1"}, "filters[id_film][$in][1]": {"2"}},
+		},
+		{
+			name: "tris et champs numérotés",
+			q:    NewQuery().Sort("id:asc").Sort("title:desc").Fields("title", "id_film"),
+			want: url.Values{
+				"sort[0]": {"id:asc"}, "sort[1]": {"title:desc"},
+				"fields[0]": {"title"}, "fields[1]": {"id_film"},
+			},
+		},
+		{
+			name: "Page remplace Limit",
+			q:    NewQuery().Limit(1).Page(3, 50),
+			want: url.Values{"pagination[page]": {"3"}, "pagination[pageSize]": {"50"}},
+		},
+		{
+			name: "Limit remplace Page",
+			q:    NewQuery().Page(3, 50).Limit(1),
+			want: url.Values{"pagination[limit]": {"1"}},
+		},
+		{
+			name: "populate",
+			q:    NewQuery().Populate("genres", "keywords"),
+			want: url.Values{"populate[genres]": {"true"}, "populate[keywords]": {"true"}},
+		},
+		{
+			name: "populate *",
+			q:    NewQuery().Populate("*"),
+			want: url.Values{"populate": {"*"}},
+		},
+		{
+			name: "relation v5 par documentId",
+			q:    NewQuery().Related("film", Document{"id": float64(12), "documentId": "abc"}),
+			want: url.Values{"filters[film][documentId][$eq]": {"abc"}},
+		},
+		{
+			name: "relation v4 par id numérique",
+			q:    NewQuery().Related("film", Document{"id": float64(12)}),
+			want: url.Values{"filters[film][id][$eq]": {"12"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.Values(); got.Encode() != tt.want.Encode() {
+				t.Errorf("Values() = %s, attendu %s", got.Encode(), tt.want.Encode())
+			}
+		})
+	}
+}
+
+func TestQueryCloneIsIndependent(t *testing.T) {
+	q := NewQuery().Sort("id:asc").Fields("title")
+	clone := q.Clone().Sort("title:asc").Fields("id_film").Eq("id_film", 1)
+	if got := q.Values().Encode(); got != "fields%5B0%5D=title&sort%5B0%5D=id%3Aasc" {
+		t.Errorf("la Query d'origine a été modifiée: %s", got)
+	}
+	want := url.Values{
+		"sort[0]": {"id:asc"}, "sort[1]": {"title:asc"},
+		"fields[0]": {"title"}, "fields[1]": {"id_film"},
+		"filters[id_film][$eq]": {"1"},
+	}
+	if got := clone.Values(); got.Encode() != want.Encode() {
+		t.Errorf("Clone() = %s, attendu %s", got.Encode(), want.Encode())
+	}
+}
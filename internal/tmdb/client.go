@@ -0,0 +1,163 @@
+// Package tmdb regroupe les appels à l'API TMDB (v3) utilisés par les synchronisations.
+// Toutes les requêtes passent par un Client typé, ce qui permet de pointer le serveur
+// vers un faux TMDB local (TMDB_BASE_URL) sans toucher aux handlers.
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL est l'URL publique de l'API TMDB v3.
+	DefaultBaseURL = "https://api.themoviedb.org/3"
+	// DefaultLanguage est la langue utilisée lorsqu'aucune n'est configurée.
+	DefaultLanguage = "fr-FR"
+	// DefaultTimeout borne la durée d'un appel TMDB.
+	DefaultTimeout = 15 * time.Second
+)
+
+// Client appelle l'API TMDB. Un Client est sûr pour un usage concurrent.
+type Client struct {
+	// BaseURL de l'API, sans slash final (ex: https://api.themoviedb.org/3).
+	BaseURL string
+	// APIKey est la clé v3, envoyée dans le paramètre api_key.
+	APIKey string
+	// BearerToken est le jeton v4 ; s'il est renseigné il remplace APIKey.
+	BearerToken string
+	// Language est envoyé dans le paramètre language si l'appel n'en précise pas.
+	Language string
+	// HTTPClient permet d'injecter un client (timeouts, transport de test…).
+	HTTPClient *http.Client
+}
+
+// New construit un Client avec les valeurs par défaut pour les champs vides.
+func New(apiKey, bearerToken string) *Client {
+	return &Client{
+		BaseURL:     DefaultBaseURL,
+		APIKey:      apiKey,
+		BearerToken: bearerToken,
+		Language:    DefaultLanguage,
+		HTTPClient:  &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// NewFromEnv construit un Client à partir des variables d'environnement :
+// API_KEY, TMDB_BEARER_TOKEN, TMDB_BASE_URL, TMDB_LANGUAGE et TMDB_TIMEOUT (ex: "20s").
+func NewFromEnv() *Client {
+	c := New(os.Getenv("API_KEY"), os.Getenv("TMDB_BEARER_TOKEN"))
+	if base := os.Getenv("TMDB_BASE_URL"); base != "" {
+		c.BaseURL = strings.TrimRight(base, "/")
+	}
+	if lang := os.Getenv("TMDB_LANGUAGE"); lang != "" {
+		c.Language = lang
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("TMDB_TIMEOUT")); err == nil && timeout > 0 {
+		c.HTTPClient.Timeout = timeout
+	}
+	return c
+}
+
+// Error est renvoyée lorsque TMDB répond avec un code HTTP d'erreur.
+// StatusCode et StatusMessage reprennent le corps {"status_code", "status_message"} de TMDB.
+type Error struct {
+	HTTPStatus    int
+	StatusCode    int    `json:"status_code"`
+	StatusMessage string `json:"status_message"`
+	Path          string
+}
+
+func (e *Error) Error() string {
+	if e.StatusMessage == "" {
+		return fmt.Sprintf("tmdb %s: code HTTP %d", e.Path, e.HTTPStatus)
+	}
+	return fmt.Sprintf("tmdb %s: code HTTP %d (status_code %d): %s", e.Path, e.HTTPStatus, e.StatusCode, e.StatusMessage)
+}
+
+// IsNotFound indique si err correspond à une ressource absente chez TMDB.
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.HTTPStatus == http.StatusNotFound
+}
+
+// get exécute un GET sur path (relatif à BaseURL) et décode la réponse dans out.
+func (c *Client) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	if params.Get("language") == "" && c.Language != "" {
+		params.Set("language", c.Language)
+	}
+	if c.BearerToken == "" && c.APIKey != "" {
+		params.Set("api_key", c.APIKey)
+	}
+
+	reqURL := c.BaseURL + path
+	if len(params) > 0 {
+		reqURL += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("tmdb %s: création de la requête: %w", path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := c.doWithRetry(ctx, httpClient, req)
+	if err != nil {
+		return fmt.Errorf("tmdb %s: %w", path, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		apiErr := &Error{HTTPStatus: res.StatusCode, Path: path}
+		body, _ := io.ReadAll(res.Body)
+		_ = json.Unmarshal(body, apiErr)
+		return apiErr
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("tmdb %s: décodage JSON: %w", path, err)
+	}
+	return nil
+}
+
+// maxRateLimitRetries est le nombre de nouvelles tentatives après une réponse 429.
+const maxRateLimitRetries = 3
+
+// doWithRetry exécute req et, si TMDB répond 429 (limite de débit), attend Retry-After
+// (1s par défaut) avant de réessayer, au plus maxRateLimitRetries fois.
+func (c *Client) doWithRetry(ctx context.Context, httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := httpClient.Do(req)
+		if err != nil || res.StatusCode != http.StatusTooManyRequests || attempt >= maxRateLimitRetries {
+			return res, err
+		}
+		wait := time.Second
+		if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && secs > 0 {
+			wait = time.Duration(secs) * time.Second
+		}
+		res.Body.Close()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaMovie et MediaTV désignent les deux familles de contenus TMDB.
+// MediaCollection (sagas) n'est utilisé que pour les traductions, MediaPerson
+// pour les personnes des résultats de recherche et de tendances.
+const (
+	MediaMovie      = "movie"
+	MediaTV         = "tv"
+	MediaCollection = "collection"
+	MediaPerson     = "person"
+)
+
+// pageParams copie params et y ajoute le numéro de page.
+func pageParams(params url.Values, page int) url.Values {
+	out := url.Values{}
+	for k, v := range params {
+		out[k] = append([]string(nil), v...)
+	}
+	if page > 0 {
+		out.Set("page", strconv.Itoa(page))
+	}
+	return out
+}
+
+// DiscoverMovies appelle discover/movie. params peut porter des filtres (sort_by, with_genres…).
+func (c *Client) DiscoverMovies(ctx context.Context, page int, params url.Values) (*MoviePage, error) {
+	var out MoviePage
+	if err := c.get(ctx, "/discover/movie", pageParams(params, page), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DiscoverTV appelle discover/tv. params peut porter des filtres (sort_by, with_genres…).
+func (c *Client) DiscoverTV(ctx context.Context, page int, params url.Values) (*TVPage, error) {
+	var out TVPage
+	if err := c.get(ctx, "/discover/tv", pageParams(params, page), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MovieRecommendations renvoie une page de recommandations pour le film movieID.
+func (c *Client) MovieRecommendations(ctx context.Context, movieID, page int) (*MoviePage, error) {
+	var out MoviePage
+	path := fmt.Sprintf("/movie/%d/recommendations", movieID)
+	if err := c.get(ctx, path, pageParams(nil, page), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TVRecommendations renvoie une page de recommandations pour la série tvID.
+func (c *Client) TVRecommendations(ctx context.Context, tvID, page int) (*TVPage, error) {
+	var out TVPage
+	path := fmt.Sprintf("/tv/%d/recommendations", tvID)
+	if err := c.get(ctx, path, pageParams(nil, page), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GenreList renvoie la liste des genres pour mediaType (MediaMovie ou MediaTV)
+// dans language (la langue du client si vide).
+func (c *Client) GenreList(ctx context.Context, mediaType, language string) ([]Genre, error) {
+	params := url.Values{}
+	if language != "" {
+		params.Set("language", language)
+	}
+	var out GenreList
+	if err := c.get(ctx, "/genre/"+mediaType+"/list", params, &out); err != nil {
+		return nil, err
+	}
+	return out.Genres, nil
+}
+
+// Configuration renvoie la configuration TMDB (images, change_keys).
+func (c *Client) Configuration(ctx context.Context) (*Configuration, error) {
+	var out Configuration
+	if err := c.get(ctx, "/configuration", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MovieDetails renvoie le détail du film movieID. appendToResponse ajoute des sous-ressources
+// à la même réponse (ex: "translations"), ce qui évite un appel par sous-ressource.
+func (c *Client) MovieDetails(ctx context.Context, movieID int, appendToResponse ...string) (*MovieDetails, error) {
+	var out MovieDetails
+	if err := c.get(ctx, fmt.Sprintf("/movie/%d", movieID), appendParams(appendToResponse), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TVDetails renvoie le détail de la série tvID (voir MovieDetails pour appendToResponse).
+func (c *Client) TVDetails(ctx context.Context, tvID int, appendToResponse ...string) (*TVDetails, error) {
+	var out TVDetails
+	if err := c.get(ctx, fmt.Sprintf("/tv/%d", tvID), appendParams(appendToResponse), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TVSeason renvoie la saison seasonNumber de la série tvID avec ses épisodes.
+func (c *Client) TVSeason(ctx context.Context, tvID, seasonNumber int) (*Season, error) {
+	var out Season
+	if err := c.get(ctx, fmt.Sprintf("/tv/%d/season/%d", tvID, seasonNumber), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// appendParams renvoie le paramètre append_to_response (nil si vide).
+func appendParams(appendToResponse []string) url.Values {
+	if len(appendToResponse) == 0 {
+		return nil
+	}
+	return url.Values{"append_to_response": {strings.Join(appendToResponse, ",")}}
+}
+
+// MaxChangesRange est l'intervalle maximal accepté par /movie/changes et /tv/changes.
+const MaxChangesRange = 14 * 24 * time.Hour
+
+// Changes renvoie une page des identifiants modifiés entre start et end (inclus, format YYYY-MM-DD)
+// pour mediaType (MediaMovie ou MediaTV).
+func (c *Client) Changes(ctx context.Context, mediaType, start, end string, page int) (*ChangesPage, error) {
+	params := url.Values{}
+	if start != "" {
+		params.Set("start_date", start)
+	}
+	if end != "" {
+		params.Set("end_date", end)
+	}
+	var out ChangesPage
+	if err := c.get(ctx, "/"+mediaType+"/changes", pageParams(params, page), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Translations renvoie toutes les traductions du titre id pour mediaType (MediaMovie, MediaTV ou MediaCollection).
+func (c *Client) Translations(ctx context.Context, mediaType string, id int) (*Translations, error) {
+	var out Translations
+	if err := c.get(ctx, fmt.Sprintf("/%s/%d/translations", mediaType, id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MovieCredits renvoie la distribution et l'équipe technique du film movieID.
+func (c *Client) MovieCredits(ctx context.Context, movieID int) (*Credits, error) {
+	var out Credits
+	if err := c.get(ctx, fmt.Sprintf("/movie/%d/credits", movieID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TVAggregateCredits renvoie les crédits de la série tvID cumulés sur toutes ses saisons,
+// aplatis en un crédit par rôle ou poste (voir AggregateCredits.Credits).
+func (c *Client) TVAggregateCredits(ctx context.Context, tvID int) (*Credits, error) {
+	var out AggregateCredits
+	if err := c.get(ctx, fmt.Sprintf("/tv/%d/aggregate_credits", tvID), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Credits(), nil
+}
+
+// PersonDetails renvoie le détail de la personne personID (voir MovieDetails pour appendToResponse,
+// ex: "combined_credits").
+func (c *Client) PersonDetails(ctx context.Context, personID int, appendToResponse ...string) (*PersonDetails, error) {
+	var out PersonDetails
+	if err := c.get(ctx, fmt.Sprintf("/person/%d", personID), appendParams(appendToResponse), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Videos renvoie les vidéos du titre id pour mediaType (MediaMovie ou MediaTV).
+// languages (codes ISO 639-1, "null" pour les vidéos sans langue) élargit la réponse
+// au-delà de la langue du client via include_video_language.
+func (c *Client) Videos(ctx context.Context, mediaType string, id int, languages ...string) (*VideoList, error) {
+	params := url.Values{}
+	if len(languages) > 0 {
+		params.Set("include_video_language", strings.Join(languages, ","))
+	}
+	var out VideoList
+	if err := c.get(ctx, fmt.Sprintf("/%s/%d/videos", mediaType, id), params, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// WatchProviders renvoie, pays par pays, où regarder le titre id de mediaType (MediaMovie ou MediaTV).
+func (c *Client) WatchProviders(ctx context.Context, mediaType string, id int) (*WatchProviders, error) {
+	var out WatchProviders
+	if err := c.get(ctx, fmt.Sprintf("/%s/%d/watch/providers", mediaType, id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// WatchProviderList renvoie les fournisseurs connus de TMDB pour mediaType,
+// limités à region (ex: "FR") si elle est renseignée.
+func (c *Client) WatchProviderList(ctx context.Context, mediaType, region string) ([]WatchProvider, error) {
+	params := url.Values{}
+	if region != "" {
+		params.Set("watch_region", region)
+	}
+	var out WatchProviderList
+	if err := c.get(ctx, "/watch/providers/"+mediaType, params, &out); err != nil {
+		return nil, err
+	}
+	return out.Results, nil
+}
+
+// ReleaseDates renvoie les dates de sortie et classifications du film movieID, pays par pays.
+func (c *Client) ReleaseDates(ctx context.Context, movieID int) (*ReleaseDates, error) {
+	var out ReleaseDates
+	if err := c.get(ctx, fmt.Sprintf("/movie/%d/release_dates", movieID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ContentRatings renvoie les classifications de la série tvID, pays par pays.
+func (c *Client) ContentRatings(ctx context.Context, tvID int) (*ContentRatings, error) {
+	var out ContentRatings
+	if err := c.get(ctx, fmt.Sprintf("/tv/%d/content_ratings", tvID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Certifications renvoie les classifications d'âge de chaque pays pour mediaType (MediaMovie ou MediaTV).
+func (c *Client) Certifications(ctx context.Context, mediaType string) (map[string][]Certification, error) {
+	var out CertificationList
+	if err := c.get(ctx, "/certification/"+mediaType+"/list", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Certifications, nil
+}
+
+// Keywords renvoie les mots-clés du titre id pour mediaType (MediaMovie ou MediaTV).
+func (c *Client) Keywords(ctx context.Context, mediaType string, id int) ([]Keyword, error) {
+	var out KeywordList
+	if err := c.get(ctx, fmt.Sprintf("/%s/%d/keywords", mediaType, id), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.All(), nil
+}
+
+// Collection renvoie la saga collectionID et ses films.
+func (c *Client) Collection(ctx context.Context, collectionID int) (*Collection, error) {
+	var out Collection
+	if err := c.get(ctx, fmt.Sprintf("/collection/%d", collectionID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Fenêtres de /trending/{media}/{window}.
+const (
+	TrendingDay  = "day"
+	TrendingWeek = "week"
+)
+
+// Listes de films (/movie/{list}) et de séries (/tv/{list}).
+const (
+	ListNowPlaying  = "now_playing"
+	ListUpcoming    = "upcoming"
+	ListTopRated    = "top_rated"
+	ListOnTheAir    = "on_the_air"
+	ListAiringToday = "airing_today"
+)
+
+// Trending renvoie une page des tendances de mediaType (MediaMovie, MediaTV ou "all")
+// sur window (TrendingDay ou TrendingWeek). /trending/all peut contenir des personnes.
+func (c *Client) Trending(ctx context.Context, mediaType, window string, page int) (*MediaPage, error) {
+	return c.mediaList(ctx, "/trending/"+mediaType+"/"+window, mediaType, pageParams(nil, page))
+}
+
+// TitleList renvoie une page de la liste list (ListNowPlaying, ListOnTheAir…) de mediaType.
+func (c *Client) TitleList(ctx context.Context, mediaType, list string, page int) (*MediaPage, error) {
+	return c.mediaList(ctx, "/"+mediaType+"/"+list, mediaType, pageParams(nil, page))
+}
+
+// SearchMulti cherche à la fois films, séries et personnes (voir Search).
+const SearchMulti = "multi"
+
+// Search appelle /search/{searchType} (MediaMovie, MediaTV, MediaPerson ou SearchMulti)
+// pour query. Les contenus adultes sont exclus.
+func (c *Client) Search(ctx context.Context, searchType, query string, page int) (*MediaPage, error) {
+	params := url.Values{"query": {query}, "include_adult": {"false"}}
+	return c.mediaList(ctx, "/search/"+searchType, searchType, pageParams(params, page))
+}
+
+// mediaList lit une page de liste et décode chaque résultat en film, série ou personne selon
+// son media_type, ou selon mediaType quand TMDB ne le précise pas (listes d'un seul type).
+func (c *Client) mediaList(ctx context.Context, path, mediaType string, params url.Values) (*MediaPage, error) {
+	var raw struct {
+		MediaPage
+		Results []json.RawMessage `json:"results"`
+	}
+	if err := c.get(ctx, path, params, &raw); err != nil {
+		return nil, err
+	}
+
+	out := raw.MediaPage
+	for _, r := range raw.Results {
+		var head struct {
+			MediaType string `json:"media_type"`
+		}
+		if err := json.Unmarshal(r, &head); err != nil {
+			return nil, fmt.Errorf("tmdb %s: décodage JSON: %w", path, err)
+		}
+		item := MediaItem{MediaType: head.MediaType}
+		if item.MediaType == "" {
+			item.MediaType = mediaType
+		}
+		var err error
+		switch item.MediaType {
+		case MediaMovie:
+			item.Movie = &Movie{}
+			err = json.Unmarshal(r, item.Movie)
+		case MediaTV:
+			item.TV = &TVShow{}
+			err = json.Unmarshal(r, item.TV)
+		case MediaPerson:
+			item.Person = &Person{}
+			err = json.Unmarshal(r, item.Person)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tmdb %s: décodage JSON: %w", path, err)
+		}
+		out.Results = append(out.Results, item)
+	}
+	return &out, nil
+}
@@ -0,0 +1,576 @@
+package tmdb
+
+import "strings"
+
+// Movie représente un film renvoyé par TMDB (discover, recommandations…).
+// GenreIDs est un slice d'entiers car TMDB renvoie [id1, id2, ...]
+type Movie struct {
+	ID               int     `json:"id"`
+	Adult            bool    `json:"adult"`
+	BackdropPath     string  `json:"backdrop_path"`
+	OriginalTitle    string  `json:"original_title"`
+	OriginalLanguage string  `json:"original_language"`
+	Overview         string  `json:"overview"`
+	PosterPath       string  `json:"poster_path"`
+	ReleaseDate      string  `json:"release_date"`
+	Title            string  `json:"title"`
+	Video            bool    `json:"video"`
+	VoteAverage      float64 `json:"vote_average"`
+	VoteCount        int     `json:"vote_count"`
+	Popularity       float64 `json:"popularity"`
+	GenreIDs         []int   `json:"genre_ids"`
+}
+
+// MoviePage enveloppe une page de films (discover/movie, recommandations…).
+type MoviePage struct {
+	Page         int     `json:"page"`
+	Results      []Movie `json:"results"`
+	TotalPages   int     `json:"total_pages"`
+	TotalResults int     `json:"total_results"`
+}
+
+// TVShow représente une série TV renvoyée par TMDB.
+type TVShow struct {
+	ID               int      `json:"id"`
+	Adult            bool     `json:"adult"`
+	BackdropPath     string   `json:"backdrop_path"`
+	OriginalName     string   `json:"original_name"`
+	OriginalLanguage string   `json:"original_language"`
+	Overview         string   `json:"overview"`
+	PosterPath       string   `json:"poster_path"`
+	Name             string   `json:"name"`
+	FirstAirDate     string   `json:"first_air_date"`
+	VoteAverage      float64  `json:"vote_average"`
+	VoteCount        int      `json:"vote_count"`
+	Popularity       float64  `json:"popularity"`
+	GenreIDs         []int    `json:"genre_ids"`
+	OriginCountry    []string `json:"origin_country"`
+}
+
+// TVPage enveloppe une page de séries TV (discover/tv, recommandations…).
+type TVPage struct {
+	Page         int      `json:"page"`
+	Results      []TVShow `json:"results"`
+	TotalPages   int      `json:"total_pages"`
+	TotalResults int      `json:"total_results"`
+}
+
+// Genre est un genre TMDB (film ou série).
+type Genre struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// GenreList enveloppe la réponse de genre/movie/list et genre/tv/list.
+type GenreList struct {
+	Genres []Genre `json:"genres"`
+}
+
+// ImageConfig décrit les URLs et tailles d'images proposées par TMDB.
+type ImageConfig struct {
+	BaseURL       string   `json:"base_url"`
+	SecureBaseURL string   `json:"secure_base_url"`
+	BackdropSizes []string `json:"backdrop_sizes"`
+	LogoSizes     []string `json:"logo_sizes"`
+	PosterSizes   []string `json:"poster_sizes"`
+	ProfileSizes  []string `json:"profile_sizes"`
+	StillSizes    []string `json:"still_sizes"`
+}
+
+// Configuration est la réponse de /configuration.
+type Configuration struct {
+	Images     ImageConfig `json:"images"`
+	ChangeKeys []string    `json:"change_keys"`
+}
+
+// MovieDetails est la réponse de /movie/{id}. TMDB y renvoie les genres
+// sous forme d'objets (genres) et non d'identifiants (genre_ids).
+type MovieDetails struct {
+	Movie
+	Genres              []Genre             `json:"genres"`
+	Runtime             int                 `json:"runtime"`
+	Budget              int64               `json:"budget"`
+	Revenue             int64               `json:"revenue"`
+	Status              string              `json:"status"`
+	Tagline             string              `json:"tagline"`
+	Homepage            string              `json:"homepage"`
+	IMDbID              string              `json:"imdb_id"`
+	SpokenLanguages     []SpokenLanguage    `json:"spoken_languages"`
+	ProductionCountries []ProductionCountry `json:"production_countries"`
+	ProductionCompanies []Company           `json:"production_companies"`
+	BelongsToCollection *CollectionSummary  `json:"belongs_to_collection"`
+
+	// Translations n'est renseigné que si "translations" est demandé dans append_to_response.
+	Translations *Translations `json:"translations,omitempty"`
+	// Keywords n'est renseigné que si "keywords" est demandé dans append_to_response.
+	Keywords *KeywordList `json:"keywords,omitempty"`
+}
+
+// SpokenLanguage est une langue parlée dans un film ou une série.
+type SpokenLanguage struct {
+	ISO639_1    string `json:"iso_639_1"`
+	Name        string `json:"name"`
+	EnglishName string `json:"english_name"`
+}
+
+// ProductionCountry est un pays de production.
+type ProductionCountry struct {
+	ISO3166_1 string `json:"iso_3166_1"`
+	Name      string `json:"name"`
+}
+
+// Company est une société de production (ou un réseau de diffusion pour les séries).
+type Company struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	LogoPath      string `json:"logo_path"`
+	OriginCountry string `json:"origin_country"`
+}
+
+// AsMovie renvoie le film avec GenreIDs rempli à partir de Genres.
+func (d *MovieDetails) AsMovie() Movie {
+	m := d.Movie
+	m.GenreIDs = genreIDs(d.Genres)
+	return m
+}
+
+// TVDetails est la réponse de /tv/{id}.
+type TVDetails struct {
+	TVShow
+	Genres           []Genre          `json:"genres"`
+	NumberOfSeasons  int              `json:"number_of_seasons"`
+	NumberOfEpisodes int              `json:"number_of_episodes"`
+	Status           string           `json:"status"`
+	Tagline          string           `json:"tagline"`
+	Homepage         string           `json:"homepage"`
+	InProduction     bool             `json:"in_production"`
+	Networks         []Company        `json:"networks"`
+	CreatedBy        []Creator        `json:"created_by"`
+	EpisodeRunTime   []int            `json:"episode_run_time"`
+	LastEpisodeToAir *EpisodeSummary  `json:"last_episode_to_air"`
+	NextEpisodeToAir *EpisodeSummary  `json:"next_episode_to_air"`
+	Seasons          []SeasonSummary  `json:"seasons"`
+	SpokenLanguages  []SpokenLanguage `json:"spoken_languages"`
+
+	// Translations n'est renseigné que si "translations" est demandé dans append_to_response.
+	Translations *Translations `json:"translations,omitempty"`
+	// Keywords n'est renseigné que si "keywords" est demandé dans append_to_response.
+	Keywords *KeywordList `json:"keywords,omitempty"`
+}
+
+// Creator est un créateur de série.
+type Creator struct {
+	ID          int    `json:"id"`
+	CreditID    string `json:"credit_id"`
+	Name        string `json:"name"`
+	ProfilePath string `json:"profile_path"`
+}
+
+// EpisodeSummary est le résumé d'un épisode (last_episode_to_air, next_episode_to_air).
+type EpisodeSummary struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	AirDate       string `json:"air_date"`
+	EpisodeNumber int    `json:"episode_number"`
+	SeasonNumber  int    `json:"season_number"`
+	Runtime       int    `json:"runtime"`
+	StillPath     string `json:"still_path"`
+}
+
+// SeasonSummary est une saison telle que listée dans /tv/{id}.
+type SeasonSummary struct {
+	ID           int    `json:"id"`
+	SeasonNumber int    `json:"season_number"`
+	Name         string `json:"name"`
+	Overview     string `json:"overview"`
+	AirDate      string `json:"air_date"`
+	EpisodeCount int    `json:"episode_count"`
+	PosterPath   string `json:"poster_path"`
+}
+
+// Season est la réponse de /tv/{id}/season/{n}.
+type Season struct {
+	ID           int       `json:"id"`
+	SeasonNumber int       `json:"season_number"`
+	Name         string    `json:"name"`
+	Overview     string    `json:"overview"`
+	AirDate      string    `json:"air_date"`
+	PosterPath   string    `json:"poster_path"`
+	Episodes     []Episode `json:"episodes"`
+}
+
+// Episode est un épisode d'une saison.
+type Episode struct {
+	ID            int     `json:"id"`
+	EpisodeNumber int     `json:"episode_number"`
+	SeasonNumber  int     `json:"season_number"`
+	Name          string  `json:"name"`
+	Overview      string  `json:"overview"`
+	AirDate       string  `json:"air_date"`
+	Runtime       int     `json:"runtime"`
+	StillPath     string  `json:"still_path"`
+	VoteAverage   float64 `json:"vote_average"`
+	VoteCount     int     `json:"vote_count"`
+}
+
+// AsTVShow renvoie la série avec GenreIDs rempli à partir de Genres.
+func (d *TVDetails) AsTVShow() TVShow {
+	s := d.TVShow
+	s.GenreIDs = genreIDs(d.Genres)
+	return s
+}
+
+func genreIDs(genres []Genre) []int {
+	ids := make([]int, 0, len(genres))
+	for _, g := range genres {
+		ids = append(ids, g.ID)
+	}
+	return ids
+}
+
+// ChangedID est une entrée de /movie/changes ou /tv/changes.
+type ChangedID struct {
+	ID    int   `json:"id"`
+	Adult *bool `json:"adult"`
+}
+
+// ChangesPage enveloppe une page de /movie/changes ou /tv/changes.
+type ChangesPage struct {
+	Page         int         `json:"page"`
+	Results      []ChangedID `json:"results"`
+	TotalPages   int         `json:"total_pages"`
+	TotalResults int         `json:"total_results"`
+}
+
+// Translation est une entrée de /movie/{id}/translations ou /tv/{id}/translations.
+type Translation struct {
+	ISO3166_1   string          `json:"iso_3166_1"`
+	ISO639_1    string          `json:"iso_639_1"`
+	Name        string          `json:"name"`
+	EnglishName string          `json:"english_name"`
+	Data        TranslationData `json:"data"`
+}
+
+// TranslationData contient les champs traduits. Title est renseigné pour les films,
+// Name pour les séries.
+type TranslationData struct {
+	Title    string `json:"title"`
+	Name     string `json:"name"`
+	Overview string `json:"overview"`
+	Tagline  string `json:"tagline"`
+	Homepage string `json:"homepage"`
+}
+
+// DisplayTitle renvoie le titre traduit (Title pour un film, Name pour une série).
+func (d TranslationData) DisplayTitle() string {
+	if d.Title != "" {
+		return d.Title
+	}
+	return d.Name
+}
+
+// Translations enveloppe la réponse de /{movie,tv}/{id}/translations.
+type Translations struct {
+	ID           int           `json:"id"`
+	Translations []Translation `json:"translations"`
+}
+
+// Find renvoie la traduction correspondant à locale (ex: "en-US") : d'abord langue et pays,
+// puis la langue seule. Renvoie nil si aucune ne correspond.
+func (t *Translations) Find(locale string) *TranslationData {
+	lang, country, _ := strings.Cut(locale, "-")
+	var byLang *TranslationData
+	for i := range t.Translations {
+		tr := &t.Translations[i]
+		if !strings.EqualFold(tr.ISO639_1, lang) {
+			continue
+		}
+		if country != "" && strings.EqualFold(tr.ISO3166_1, country) {
+			return &tr.Data
+		}
+		if byLang == nil {
+			byLang = &tr.Data
+		}
+	}
+	return byLang
+}
+
+// Credits est la réponse de /movie/{id}/credits (ou la forme aplatie de /tv/{id}/aggregate_credits).
+type Credits struct {
+	ID   int          `json:"id"`
+	Cast []CastCredit `json:"cast"`
+	Crew []CrewCredit `json:"crew"`
+}
+
+// Person regroupe les champs communs à toutes les apparitions d'une personne.
+type Person struct {
+	ID                 int    `json:"id"`
+	Name               string `json:"name"`
+	ProfilePath        string `json:"profile_path"`
+	KnownForDepartment string `json:"known_for_department"`
+}
+
+// CastCredit est un rôle d'acteur. EpisodeCount n'est renseigné que pour les séries.
+type CastCredit struct {
+	Person
+	CreditID     string `json:"credit_id"`
+	Character    string `json:"character"`
+	Order        int    `json:"order"`
+	EpisodeCount int    `json:"episode_count,omitempty"`
+}
+
+// CrewCredit est un poste technique. EpisodeCount n'est renseigné que pour les séries.
+type CrewCredit struct {
+	Person
+	CreditID     string `json:"credit_id"`
+	Department   string `json:"department"`
+	Job          string `json:"job"`
+	EpisodeCount int    `json:"episode_count,omitempty"`
+}
+
+// AggregateCredits est la réponse de /tv/{id}/aggregate_credits : une entrée par personne,
+// avec tous ses rôles (roles) ou postes (jobs) sur l'ensemble des saisons.
+type AggregateCredits struct {
+	ID   int `json:"id"`
+	Cast []struct {
+		Person
+		Order int `json:"order"`
+		Roles []struct {
+			CreditID     string `json:"credit_id"`
+			Character    string `json:"character"`
+			EpisodeCount int    `json:"episode_count"`
+		} `json:"roles"`
+	} `json:"cast"`
+	Crew []struct {
+		Person
+		Department string `json:"department"`
+		Jobs       []struct {
+			CreditID     string `json:"credit_id"`
+			Job          string `json:"job"`
+			EpisodeCount int    `json:"episode_count"`
+		} `json:"jobs"`
+	} `json:"crew"`
+}
+
+// Credits aplatit les crédits agrégés : un CastCredit par rôle et un CrewCredit par poste.
+func (a *AggregateCredits) Credits() *Credits {
+	out := &Credits{ID: a.ID}
+	for _, c := range a.Cast {
+		for _, r := range c.Roles {
+			out.Cast = append(out.Cast, CastCredit{Person: c.Person, CreditID: r.CreditID, Character: r.Character, Order: c.Order, EpisodeCount: r.EpisodeCount})
+		}
+	}
+	for _, c := range a.Crew {
+		for _, j := range c.Jobs {
+			out.Crew = append(out.Crew, CrewCredit{Person: c.Person, CreditID: j.CreditID, Department: c.Department, Job: j.Job, EpisodeCount: j.EpisodeCount})
+		}
+	}
+	return out
+}
+
+// PersonDetails est la réponse de /person/{id}.
+type PersonDetails struct {
+	Person
+	Biography    string   `json:"biography"`
+	Birthday     string   `json:"birthday"`
+	Deathday     string   `json:"deathday"`
+	PlaceOfBirth string   `json:"place_of_birth"`
+	Gender       int      `json:"gender"`
+	IMDbID       string   `json:"imdb_id"`
+	Homepage     string   `json:"homepage"`
+	AlsoKnownAs  []string `json:"also_known_as"`
+	Popularity   float64  `json:"popularity"`
+	// CombinedCredits n'est renseigné que si "combined_credits" est demandé dans append_to_response.
+	CombinedCredits *CombinedCredits `json:"combined_credits,omitempty"`
+}
+
+// CombinedCredits est la filmographie d'une personne, films et séries confondus.
+type CombinedCredits struct {
+	Cast []PersonCredit `json:"cast"`
+	Crew []PersonCredit `json:"crew"`
+}
+
+// PersonCredit est une apparition d'une personne dans un titre (MediaType vaut MediaMovie ou MediaTV).
+type PersonCredit struct {
+	ID         int    `json:"id"`
+	MediaType  string `json:"media_type"`
+	CreditID   string `json:"credit_id"`
+	Title      string `json:"title"`
+	Name       string `json:"name"`
+	Character  string `json:"character"`
+	Department string `json:"department"`
+	Job        string `json:"job"`
+}
+
+// Video est une vidéo (bande-annonce, teaser, extrait…) hébergée sur YouTube ou Vimeo.
+type Video struct {
+	ID          string `json:"id"`
+	ISO639_1    string `json:"iso_639_1"`
+	ISO3166_1   string `json:"iso_3166_1"`
+	Name        string `json:"name"`
+	Key         string `json:"key"`
+	Site        string `json:"site"`
+	Size        int    `json:"size"`
+	Type        string `json:"type"`
+	Official    bool   `json:"official"`
+	PublishedAt string `json:"published_at"`
+}
+
+// VideoList est la réponse de /{movie,tv}/{id}/videos.
+type VideoList struct {
+	ID      int     `json:"id"`
+	Results []Video `json:"results"`
+}
+
+// WatchProvider est un service de streaming, location ou achat (Netflix, Canal+…).
+type WatchProvider struct {
+	ProviderID      int    `json:"provider_id"`
+	ProviderName    string `json:"provider_name"`
+	LogoPath        string `json:"logo_path"`
+	DisplayPriority int    `json:"display_priority"`
+	// DisplayPriorities n'est renseigné que par /watch/providers/{movie,tv} (priorité par pays).
+	DisplayPriorities map[string]int `json:"display_priorities,omitempty"`
+}
+
+// WatchProviderList est la réponse de /watch/providers/{movie,tv}.
+type WatchProviderList struct {
+	Results []WatchProvider `json:"results"`
+}
+
+// WatchAvailability liste les fournisseurs d'un titre dans un pays, par mode d'accès.
+type WatchAvailability struct {
+	Link     string          `json:"link"`
+	Flatrate []WatchProvider `json:"flatrate"`
+	Rent     []WatchProvider `json:"rent"`
+	Buy      []WatchProvider `json:"buy"`
+	Free     []WatchProvider `json:"free"`
+	Ads      []WatchProvider `json:"ads"`
+}
+
+// WatchProviders est la réponse de /{movie,tv}/{id}/watch/providers, indexée par pays (ex: "FR").
+type WatchProviders struct {
+	ID      int                          `json:"id"`
+	Results map[string]WatchAvailability `json:"results"`
+}
+
+// Types de sortie de /movie/{id}/release_dates.
+const (
+	ReleasePremiere          = 1
+	ReleaseTheatricalLimited = 2
+	ReleaseTheatrical        = 3
+	ReleaseDigital           = 4
+	ReleasePhysical          = 5
+	ReleaseTV                = 6
+)
+
+// ReleaseDate est une sortie d'un film dans un pays, avec sa classification.
+type ReleaseDate struct {
+	Certification string   `json:"certification"`
+	Descriptors   []string `json:"descriptors"`
+	ISO639_1      string   `json:"iso_639_1"`
+	Note          string   `json:"note"`
+	ReleaseDate   string   `json:"release_date"`
+	Type          int      `json:"type"`
+}
+
+// ReleaseDates est la réponse de /movie/{id}/release_dates : les sorties par pays.
+type ReleaseDates struct {
+	ID      int `json:"id"`
+	Results []struct {
+		ISO3166_1    string        `json:"iso_3166_1"`
+		ReleaseDates []ReleaseDate `json:"release_dates"`
+	} `json:"results"`
+}
+
+// ContentRating est la classification d'une série dans un pays.
+type ContentRating struct {
+	ISO3166_1   string   `json:"iso_3166_1"`
+	Rating      string   `json:"rating"`
+	Descriptors []string `json:"descriptors"`
+}
+
+// ContentRatings est la réponse de /tv/{id}/content_ratings.
+type ContentRatings struct {
+	ID      int             `json:"id"`
+	Results []ContentRating `json:"results"`
+}
+
+// Certification est une classification d'âge d'un pays (ex: "-12" en France).
+type Certification struct {
+	Certification string `json:"certification"`
+	Meaning       string `json:"meaning"`
+	Order         int    `json:"order"`
+}
+
+// CertificationList est la réponse de /certification/{movie,tv}/list, indexée par pays.
+type CertificationList struct {
+	Certifications map[string][]Certification `json:"certifications"`
+}
+
+// Keyword est un mot-clé TMDB (ex: "time travel").
+type Keyword struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// KeywordList est la réponse de /{movie,tv}/{id}/keywords : TMDB renvoie la liste
+// dans keywords pour les films et dans results pour les séries.
+type KeywordList struct {
+	ID       int       `json:"id"`
+	Keywords []Keyword `json:"keywords"`
+	Results  []Keyword `json:"results"`
+}
+
+// All renvoie les mots-clés, quel que soit le type de titre.
+func (k *KeywordList) All() []Keyword {
+	if len(k.Keywords) > 0 {
+		return k.Keywords
+	}
+	return k.Results
+}
+
+// CollectionSummary est la saga à laquelle appartient un film (belongs_to_collection).
+type CollectionSummary struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	PosterPath   string `json:"poster_path"`
+	BackdropPath string `json:"backdrop_path"`
+}
+
+// Collection est la réponse de /collection/{id} : une saga et ses films (parts).
+type Collection struct {
+	CollectionSummary
+	Overview string  `json:"overview"`
+	Parts    []Movie `json:"parts"`
+}
+
+// MediaItem est un résultat de liste ou de recherche qui peut être un film, une série ou,
+// pour /search et /trending/all, une personne. Exactement un de Movie, TV et Person est
+// renseigné, selon MediaType.
+type MediaItem struct {
+	MediaType string
+	Movie     *Movie
+	TV        *TVShow
+	Person    *Person
+}
+
+// ID renvoie l'identifiant TMDB du film, de la série ou de la personne.
+func (m MediaItem) ID() int {
+	switch {
+	case m.Movie != nil:
+		return m.Movie.ID
+	case m.TV != nil:
+		return m.TV.ID
+	case m.Person != nil:
+		return m.Person.ID
+	}
+	return 0
+}
+
+// MediaPage est une page de liste (tendances, à l'affiche…) ou de recherche aux résultats mixtes.
+type MediaPage struct {
+	Page         int         `json:"page"`
+	TotalPages   int         `json:"total_pages"`
+	TotalResults int         `json:"total_results"`
+	Results      []MediaItem `json:"-"`
+}